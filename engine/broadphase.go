@@ -0,0 +1,75 @@
+package engine
+
+import (
+	neonMath "Neon/engine/math"
+	"Neon/entities"
+)
+
+// BodyPair is a pair of bodies that the broadphase believes may be colliding
+type BodyPair struct {
+	A, B entities.Body
+}
+
+// Broadphase is responsible for cheaply culling pairs of bodies that cannot possibly be colliding, only the pairs it reports are sent to DetermineCollision
+type Broadphase interface {
+	Insert(body entities.Body)
+	Remove(body entities.Body)
+
+	// Update refreshes the broadphase after the bodies within it have moved
+	Update()
+	// ComputePairs returns every pair of bodies that could potentially be colliding
+	ComputePairs() []BodyPair
+	// QueryAABB returns every body whose bounding box overlaps the provided box
+	QueryAABB(box neonMath.AABB) []entities.Body
+}
+
+// NaiveBroadphase doesnt actually cull anything, every single pair of bodies gets reported
+// its only really useful for tiny scenes and for benchmarking the other broadphases against
+type NaiveBroadphase struct {
+	bodies []entities.Body
+}
+
+// NewNaiveBroadphase creates an empty naive broadphase
+func NewNaiveBroadphase() *NaiveBroadphase {
+	return &NaiveBroadphase{}
+}
+
+// Insert adds a body to the broadphase
+func (naive *NaiveBroadphase) Insert(body entities.Body) {
+	naive.bodies = append(naive.bodies, body)
+}
+
+// Remove removes a body from the broadphase
+func (naive *NaiveBroadphase) Remove(body entities.Body) {
+	for i, b := range naive.bodies {
+		if b == body {
+			naive.bodies = append(naive.bodies[:i], naive.bodies[i+1:]...)
+			return
+		}
+	}
+}
+
+// Update is a no-op as there is nothing to refresh
+func (naive *NaiveBroadphase) Update() {}
+
+// ComputePairs returns every pair of bodies
+func (naive *NaiveBroadphase) ComputePairs() []BodyPair {
+	pairs := []BodyPair{}
+	for i, a := range naive.bodies {
+		for _, b := range naive.bodies[i+1:] {
+			pairs = append(pairs, BodyPair{A: a, B: b})
+		}
+	}
+	return pairs
+}
+
+// QueryAABB returns every body whose bounding box overlaps the provided box
+func (naive *NaiveBroadphase) QueryAABB(box neonMath.AABB) []entities.Body {
+	bodies := []entities.Body{}
+	for _, b := range naive.bodies {
+		if b.GetBoundingBox().Overlaps(box) {
+			bodies = append(bodies, b)
+		}
+	}
+	return bodies
+}
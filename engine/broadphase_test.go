@@ -0,0 +1,202 @@
+package engine
+
+import (
+	neonMath "Neon/engine/math"
+	"Neon/entities"
+	"math/rand"
+	"testing"
+)
+
+// randomCircles scatters a bunch of circles around a 1000x1000 box
+func randomCircles(n int, random *rand.Rand) []*entities.Circle {
+	circles := []*entities.Circle{}
+	for i := 0; i < n; i++ {
+		circle := entities.NewCircle(neonMath.Vector2D{X: random.Float64() * 1000, Y: random.Float64() * 1000}, 5+random.Float64()*30)
+		circles = append(circles, &circle)
+	}
+	return circles
+}
+
+// checkBroadphase makes sure that every pair of overlapping boxes is reported by the broadphase
+func checkBroadphase(t *testing.T, broadphase Broadphase, circles []*entities.Circle) {
+	reported := map[BodyPair]bool{}
+	for _, pair := range broadphase.ComputePairs() {
+		if reported[pair] || reported[BodyPair{A: pair.B, B: pair.A}] {
+			t.Errorf("pair %v was reported twice", pair)
+		}
+		reported[pair] = true
+	}
+
+	for i, a := range circles {
+		for _, b := range circles[i+1:] {
+			if a.GetBoundingBox().Overlaps(b.GetBoundingBox()) && !reported[BodyPair{A: a, B: b}] && !reported[BodyPair{A: b, B: a}] {
+				t.Errorf("overlapping pair at %v and %v was not reported", a.State.CentroidPosition, b.State.CentroidPosition)
+			}
+		}
+	}
+}
+
+func TestAABBTreePairs(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	circles := randomCircles(200, random)
+
+	tree := NewAABBTree(aabbMargin)
+	for _, circle := range circles {
+		tree.Insert(circle)
+	}
+	checkBroadphase(t, tree, circles)
+
+	// shuffle everything around and make sure the tree keeps up
+	for _, circle := range circles {
+		circle.State.CentroidPosition = circle.State.CentroidPosition.Add(neonMath.Vector2D{X: random.Float64()*100 - 50, Y: random.Float64()*100 - 50})
+	}
+	tree.Update()
+	checkBroadphase(t, tree, circles)
+
+	for _, circle := range circles[:100] {
+		tree.Remove(circle)
+	}
+	checkBroadphase(t, tree, circles[100:])
+	if queried := tree.QueryAABB(neonMath.AABB{Min: neonMath.Vector2D{X: -100, Y: -100}, Max: neonMath.Vector2D{X: 2000, Y: 2000}}); len(queried) != 100 {
+		t.Errorf("expected the query to return all 100 remaining bodies, got %d", len(queried))
+	}
+}
+
+func TestQuadtreePairs(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	circles := randomCircles(200, random)
+
+	tree := NewQuadtree(neonMath.AABB{Max: neonMath.Vector2D{X: 1000, Y: 1000}}, 6, 4)
+	for _, circle := range circles {
+		tree.Insert(circle)
+	}
+	checkBroadphase(t, tree, circles)
+
+	// move some of the circles outside the bounds of the tree entirely
+	for _, circle := range circles {
+		circle.State.CentroidPosition = circle.State.CentroidPosition.Add(neonMath.Vector2D{X: random.Float64()*200 - 100, Y: random.Float64()*200 - 100})
+	}
+	tree.Update()
+	checkBroadphase(t, tree, circles)
+
+	for _, circle := range circles[:150] {
+		tree.Remove(circle)
+	}
+	checkBroadphase(t, tree, circles[150:])
+
+	region := neonMath.AABB{Min: neonMath.Vector2D{X: 250, Y: 250}, Max: neonMath.Vector2D{X: 750, Y: 750}}
+	queried := map[entities.Body]bool{}
+	for _, body := range tree.QueryAABB(region) {
+		queried[body] = true
+	}
+	for _, circle := range circles[150:] {
+		if circle.GetBoundingBox().Overlaps(region) != queried[circle] {
+			t.Errorf("circle at %v was incorrectly queried", circle.State.CentroidPosition)
+		}
+	}
+}
+
+func TestQuadtreeDeterminism(t *testing.T) {
+	// builds and shuffles the same tree from scratch, reporting where each pair is in the order the pairs came out
+	run := func() [][2]neonMath.Vector2D {
+		random := rand.New(rand.NewSource(1))
+		circles := randomCircles(200, random)
+
+		tree := NewQuadtree(neonMath.AABB{Max: neonMath.Vector2D{X: 1000, Y: 1000}}, 6, 4)
+		for _, circle := range circles {
+			tree.Insert(circle)
+		}
+		for _, circle := range circles {
+			circle.State.CentroidPosition = circle.State.CentroidPosition.Add(neonMath.Vector2D{X: random.Float64()*200 - 100, Y: random.Float64()*200 - 100})
+		}
+		tree.Update()
+
+		pairs := [][2]neonMath.Vector2D{}
+		for _, pair := range tree.ComputePairs() {
+			pairs = append(pairs, [2]neonMath.Vector2D{pair.A.GetState().CentroidPosition, pair.B.GetState().CentroidPosition})
+		}
+		return pairs
+	}
+
+	first, second := run(), run()
+	if len(first) != len(second) {
+		t.Fatalf("the same tree reported %d pairs and then %d", len(first), len(second))
+	}
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("the same tree reported its pairs in a different order, pair %d was %v and then %v", i, first[i], second[i])
+		}
+	}
+}
+
+func TestSweepAndPrunePairs(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	circles := randomCircles(200, random)
+
+	sap := NewSweepAndPrune()
+	live := map[BodyPair]bool{}
+	sap.AddPairCallbacks(
+		func(pair BodyPair) { live[pair] = true },
+		func(pair BodyPair) { delete(live, pair) })
+
+	for _, circle := range circles {
+		sap.Insert(circle)
+	}
+	checkBroadphase(t, sap, circles)
+
+	for step := 0; step < 10; step++ {
+		for _, circle := range circles {
+			circle.State.CentroidPosition = circle.State.CentroidPosition.Add(neonMath.Vector2D{X: random.Float64()*40 - 20, Y: random.Float64()*40 - 20})
+		}
+		sap.Update()
+		checkBroadphase(t, sap, circles)
+
+		// sweep and prune is exact so the pairs reported by the events should match the pairs that actually overlap
+		pairs := sap.ComputePairs()
+		if len(pairs) != len(live) {
+			t.Fatalf("expected the events to track %d pairs, got %d", len(pairs), len(live))
+		}
+		for _, pair := range pairs {
+			if !pair.A.GetBoundingBox().Overlaps(pair.B.GetBoundingBox()) || !live[pair] {
+				t.Errorf("pair %v should not be reported", pair)
+			}
+		}
+	}
+
+	for _, circle := range circles[:100] {
+		sap.Remove(circle)
+	}
+	checkBroadphase(t, sap, circles[100:])
+	if len(sap.ComputePairs()) != len(live) {
+		t.Errorf("removing bodies should have removed their pairs")
+	}
+}
+
+func BenchmarkBroadphases(b *testing.B) {
+	broadphases := map[string]func() Broadphase{
+		"Naive":         func() Broadphase { return NewNaiveBroadphase() },
+		"AABBTree":      func() Broadphase { return NewAABBTree(aabbMargin) },
+		"Quadtree":      func() Broadphase { return NewQuadtree(neonMath.AABB{Max: neonMath.Vector2D{X: 1000, Y: 1000}}, 8, 8) },
+		"SweepAndPrune": func() Broadphase { return NewSweepAndPrune() },
+	}
+
+	for name, constructor := range broadphases {
+		b.Run(name, func(b *testing.B) {
+			random := rand.New(rand.NewSource(1))
+			circles := randomCircles(500, random)
+			broadphase := constructor()
+			for _, circle := range circles {
+				broadphase.Insert(circle)
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				for _, circle := range circles {
+					circle.State.CentroidPosition = circle.State.CentroidPosition.Add(neonMath.Vector2D{X: random.Float64()*2 - 1, Y: random.Float64()*2 - 1})
+				}
+				broadphase.Update()
+				broadphase.ComputePairs()
+			}
+		})
+	}
+}
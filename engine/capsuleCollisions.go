@@ -0,0 +1,220 @@
+package engine
+
+import (
+	neonMath "Neon/engine/math"
+	"Neon/entities"
+	"math"
+)
+
+// parallelTolerance is how close the sine of the angle between two segments has to be to 0 before we treat them as parallel
+const parallelTolerance float64 = 0.05
+
+// ComputeCapsuleContactManifold computes the contact manifold between two capsules, the MTV points from A to B
+func ComputeCapsuleContactManifold(capsuleA, capsuleB *entities.Capsule) ContactManifold {
+	segmentA, segmentB := capsuleA.GetSegment(), capsuleB.GetSegment()
+	closestA, closestB := neonMath.ClosestPointsBetweenIntervals(segmentA, segmentB)
+	separation := closestB.Sub(closestA)
+	distance := separation.Length()
+
+	radii := capsuleA.Radius + capsuleB.Radius
+	if distance >= radii {
+		return ContactManifold{
+			ContactCount: 0,
+		}
+	}
+
+	// If the cores of the capsules actually cross then the closest points are meaningless and we need to push out along one of the segment normals
+	collisionNormal, depth := separation.Scale(1.0/distance), radii-distance
+	if distance <= equalityTolerance {
+		collisionNormal, depth = capsuleCrossingMTV(capsuleA, capsuleB)
+	}
+
+	contactPoints, contactDepths := []neonMath.Vector2D{closestA.Add(collisionNormal.Scale(capsuleA.Radius - depth/2.0))}, []float64{depth}
+	if parallelContacts, parallelDepths := parallelCapsuleContacts(capsuleA, capsuleB, collisionNormal); len(parallelContacts) != 0 {
+		contactPoints, contactDepths = parallelContacts, parallelDepths
+	}
+
+	return ContactManifold{
+		IncidentFrame:  capsuleB,
+		ReferenceFrame: capsuleA,
+
+		MTV:             collisionNormal.Scale(depth),
+		ContactCount:    len(contactPoints),
+		CollisionPoints: contactPoints,
+		ContactDepths:   contactDepths,
+	}
+}
+
+// capsuleCrossingMTV determines the cheapest way of separating two capsules whose segments intersect, the result is a normal pointing from A to B and a depth
+func capsuleCrossingMTV(capsuleA, capsuleB *entities.Capsule) (neonMath.Vector2D, float64) {
+	bestNormal, bestDepth := neonMath.ZeroVec2D, math.Inf(1)
+
+	for _, axis := range []neonMath.Vector2D{capsuleA.SegmentNormal(), capsuleB.SegmentNormal()} {
+		projectionA, projectionB := capsuleA.AxisProjection(axis), capsuleB.AxisProjection(axis)
+
+		if forwards := projectionA[1] - projectionB[0]; forwards < bestDepth {
+			bestNormal, bestDepth = axis, forwards
+		}
+		if backwards := projectionB[1] - projectionA[0]; backwards < bestDepth {
+			bestNormal, bestDepth = axis.Scale(-1.0), backwards
+		}
+	}
+	return bestNormal, bestDepth
+}
+
+// parallelCapsuleContacts generates a pair of contact points for capsules that are lying alongside each other, a single contact point here would just make them rock back and forth
+// if the capsules are not parallel then no points are generated
+func parallelCapsuleContacts(capsuleA, capsuleB *entities.Capsule, collisionNormal neonMath.Vector2D) ([]neonMath.Vector2D, []float64) {
+	segmentA, segmentB := capsuleA.GetSegment(), capsuleB.GetSegment()
+	directionA, directionB := segmentA[1].Sub(segmentA[0]), segmentB[1].Sub(segmentB[0])
+	lengthA := directionA.Length()
+	if lengthA <= equalityTolerance || directionB.Length() <= equalityTolerance ||
+		math.Abs(directionA.Normalise().CrossMag(directionB.Normalise())) > parallelTolerance {
+		return nil, nil
+	}
+	directionA = directionA.Scale(1.0 / lengthA)
+
+	// Clip B onto the extent of A
+	t0, t1 := segmentB[0].Sub(segmentA[0]).Dot(directionA), segmentB[1].Sub(segmentA[0]).Dot(directionA)
+	lower, upper := math.Max(0, math.Min(t0, t1)), math.Min(lengthA, math.Max(t0, t1))
+	if upper-lower <= equalityTolerance {
+		return nil, nil
+	}
+
+	contactPoints, contactDepths := []neonMath.Vector2D{}, []float64{}
+	for _, t := range []float64{lower, upper} {
+		pointA := segmentA[0].Add(directionA.Scale(t))
+		pointB := neonMath.ClosestPointOnInterval(pointA, segmentB)
+
+		if depth := capsuleA.Radius + capsuleB.Radius - pointB.Sub(pointA).Dot(collisionNormal); depth > 0 {
+			contactPoints = append(contactPoints, pointA.Add(collisionNormal.Scale(capsuleA.Radius-depth/2.0)))
+			contactDepths = append(contactDepths, depth)
+		}
+	}
+	return contactPoints, contactDepths
+}
+
+// ComputeCapsuleCircleContactManifold computes the contact manifold between a capsule and a circle, the MTV points from the capsule to the circle
+func ComputeCapsuleCircleContactManifold(capsule *entities.Capsule, circle *entities.Circle) ContactManifold {
+	closest := neonMath.ClosestPointOnInterval(circle.State.CentroidPosition, capsule.GetSegment())
+	separation := circle.State.CentroidPosition.Sub(closest)
+	distance := separation.Length()
+
+	depth := capsule.Radius + circle.Radius - distance
+	if depth <= 0 {
+		return ContactManifold{
+			ContactCount: 0,
+		}
+	}
+
+	// a circle sitting right on the core of the capsule gets pushed out sideways
+	collisionNormal := capsule.SegmentNormal()
+	if distance > equalityTolerance {
+		collisionNormal = separation.Scale(1.0 / distance)
+	}
+	contactPoint := closest.Add(collisionNormal.Scale(capsule.Radius - depth/2.0))
+
+	return ContactManifold{
+		IncidentFrame:  circle,
+		ReferenceFrame: capsule,
+
+		MTV:             collisionNormal.Scale(depth),
+		ContactCount:    1,
+		CollisionPoints: []neonMath.Vector2D{contactPoint},
+		ContactDepths:   []float64{depth},
+	}
+}
+
+// ComputeCapsulePolygonContactManifold computes the contact manifold between a capsule and a polygon, the polygon is always the reference frame
+// so the MTV points from the polygon towards the capsule
+func ComputeCapsulePolygonContactManifold(capsule *entities.Capsule, poly *entities.Polygon) ContactManifold {
+	mtv, referenceFace := entities.CapsulePolygonSAT(*capsule, *poly)
+	if math.Abs(mtv.Length()) <= equalityTolerance {
+		return ContactManifold{
+			ContactCount: 0,
+		}
+	}
+	collisionNormal := mtv.Normalise()
+
+	// If the capsule is resting against one of the faces then we clip its segment against that face, this way a capsule lying on the ground gets two contact points
+	contactPoints, contactDepths := []neonMath.Vector2D{}, []float64{}
+	if referenceFace != nil {
+		contactPoints, contactDepths = capsuleFaceClip(capsule, poly.GetEdgeCoordinates(referenceFace), collisionNormal)
+	}
+
+	// Otherwise the contact is just the corner of the polygon that has been pushed into the capsule
+	if len(contactPoints) == 0 {
+		corner, _ := poly.GetSupportingPoint(collisionNormal)
+		contactPoints, contactDepths = []neonMath.Vector2D{corner}, []float64{mtv.Length()}
+	}
+
+	return ContactManifold{
+		IncidentFrame:  capsule,
+		ReferenceFrame: poly,
+
+		ReferenceFace: referenceFace,
+
+		MTV:             mtv,
+		ContactCount:    len(contactPoints),
+		CollisionPoints: contactPoints,
+		ContactDepths:   contactDepths,
+	}
+}
+
+// capsuleFaceClip clips the segment of a capsule to the extent of a reference face and returns all the points along it that lie within the radius of the face
+// the returned points lie on the reference face itself
+func capsuleFaceClip(capsule *entities.Capsule, referenceFace [2]neonMath.Vector2D, collisionNormal neonMath.Vector2D) ([]neonMath.Vector2D, []float64) {
+	segment := capsule.GetSegment()
+	faceDirection := referenceFace[1].Sub(referenceFace[0])
+
+	// parametrise the segment in terms of the face, the section of the segment that projects outside of [0, 1] gets clipped away
+	t0, t1 := segment[0].Sub(referenceFace[0]).ScalarProject(faceDirection), segment[1].Sub(referenceFace[0]).ScalarProject(faceDirection)
+	lower, upper := 0.0, 1.0
+	if math.Abs(t1-t0) <= equalityTolerance {
+		// the segment is perpendicular to the face so its either entirely beside it or entirely outside
+		if t0 < 0 || t0 > 1 {
+			return nil, nil
+		}
+	} else {
+		muA, muB := -t0/(t1-t0), (1-t0)/(t1-t0)
+		lower, upper = math.Max(0, math.Min(muA, muB)), math.Min(1, math.Max(muA, muB))
+		if lower > upper {
+			return nil, nil
+		}
+	}
+	clipped := []neonMath.Vector2D{
+		segment[0].Add(segment[1].Sub(segment[0]).Scale(lower)),
+		segment[0].Add(segment[1].Sub(segment[0]).Scale(upper)),
+	}
+	if clipped[1].Sub(clipped[0]).Length() <= equalityTolerance {
+		clipped = clipped[:1]
+	}
+
+	contactPoints, contactDepths := []neonMath.Vector2D{}, []float64{}
+	for _, point := range clipped {
+		distance := point.Sub(referenceFace[0]).Dot(collisionNormal)
+		if depth := capsule.Radius - distance; depth > 0 {
+			contactPoints = append(contactPoints, point.Sub(collisionNormal.Scale(distance)))
+			contactDepths = append(contactDepths, depth)
+		}
+	}
+	return contactPoints, contactDepths
+}
+
+// DetermineCapsuleCollision determines if two capsules collide
+func DetermineCapsuleCollision(capsuleA, capsuleB *entities.Capsule) (bool, ContactManifold) {
+	contactManifold := ComputeCapsuleContactManifold(capsuleA, capsuleB)
+	return contactManifold.ContactCount != 0, contactManifold
+}
+
+// DetermineCapsuleCircleCollision determines if a capsule and a circle collide
+func DetermineCapsuleCircleCollision(capsule *entities.Capsule, circle *entities.Circle) (bool, ContactManifold) {
+	contactManifold := ComputeCapsuleCircleContactManifold(capsule, circle)
+	return contactManifold.ContactCount != 0, contactManifold
+}
+
+// DetermineCapsulePolygonCollision determines if a capsule and a polygon collide
+func DetermineCapsulePolygonCollision(capsule *entities.Capsule, poly *entities.Polygon) (bool, ContactManifold) {
+	contactManifold := ComputeCapsulePolygonContactManifold(capsule, poly)
+	return contactManifold.ContactCount != 0, contactManifold
+}
@@ -0,0 +1,86 @@
+package engine
+
+import (
+	neonMath "Neon/engine/math"
+	"Neon/entities"
+	"math"
+)
+
+/*
+	Continuous collision detection stops fast moving bodies (bullets) from tunnelling straight through thin geometry. Instead of jumping straight to
+	the end of the timestep a bullet is swept along its path in sub-steps that are small enough that it can never skip over anything, once a sub-step
+	ends up overlapping something the time of impact is narrowed down with a bisection and the bullet is left there, just barely touching what it hit.
+	The solver then deals with the contact on the next timestep. Everything that the bullet is swept against is assumed to be stationary
+*/
+
+// advanceBullet integrates a bullet by dt while making sure it stops at the first time of impact with any of the other tracked bodies
+func (receiver *PhysicsManager) advanceBullet(bullet entities.Body, dt float64) {
+	state := bullet.GetState()
+	speed := state.Velocity.Length() * neonMath.Metre
+
+	// slow bullets cant possibly skip over anything
+	box := bullet.GetBoundingBox()
+	extent := math.Min(box.Max.X-box.Min.X, box.Max.Y-box.Min.Y)
+	if speed*dt <= 0.5*extent {
+		bullet.NextTimeStep(dt)
+		return
+	}
+
+	// only things along the path of the bullet are worth checking, anything it is already touching is left for the solver to handle
+	sweptBox := box.Union(box.Translate(state.Velocity.Scale(neonMath.Metre * dt)))
+	candidates := []entities.Body{}
+	for _, body := range receiver.broadphase.QueryAABB(sweptBox) {
+		if body == bullet || body.GetState().Sensor || !receiver.shouldCollide(bullet, body) {
+			continue
+		}
+		if collides, _ := DetermineCollision(bullet, body); !collides {
+			candidates = append(candidates, body)
+		}
+	}
+
+	sweep(bullet, candidates, speed*dt, func(fraction float64) {
+		bullet.NextTimeStep(fraction * dt)
+	})
+}
+
+// sweep moves a body along a path (that is distance long) in sub-steps until it hits one of the candidates, move advances the body by a fraction of the path
+// If anything is hit then the time of impact is narrowed down with a bisection, the body is always left on the overlapping side of the impact
+// The fraction of the path that the body covered is returned
+func sweep(body entities.Body, candidates []entities.Body, distance float64, move func(fraction float64)) (float64, bool) {
+	// the sub-steps have to be short enough that the body always overlaps itself between consecutive sub-steps
+	box := body.GetBoundingBox()
+	extent := math.Max(math.Min(box.Max.X-box.Min.X, box.Max.Y-box.Min.Y), toiTolerance)
+	subSteps := int(math.Max(1, math.Ceil(distance/(0.5*extent))))
+	stepSize := 1.0 / float64(subSteps)
+
+	for i := 0; i < subSteps; i++ {
+		move(stepSize)
+		if !hitsAny(body, candidates) {
+			continue
+		}
+
+		lo, hi := 0.0, stepSize
+		for (hi-lo)*distance > toiTolerance {
+			mid := 0.5 * (lo + hi)
+			move(mid - hi)
+			if hitsAny(body, candidates) {
+				hi = mid
+			} else {
+				move(hi - mid)
+				lo = mid
+			}
+		}
+		return float64(i)*stepSize + hi, true
+	}
+	return 1, false
+}
+
+// hitsAny determines if a body is colliding with any of the candidates
+func hitsAny(body entities.Body, candidates []entities.Body) bool {
+	for _, candidate := range candidates {
+		if collides, _ := DetermineCollision(body, candidate); collides {
+			return true
+		}
+	}
+	return false
+}
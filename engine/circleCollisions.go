@@ -0,0 +1,86 @@
+package engine
+
+import (
+	neonMath "Neon/engine/math"
+	"Neon/entities"
+)
+
+// ComputeCircleContactManifold computes the contact manifold for two circles, as with polygons the MTV points from A to B
+// Circles only ever touch at a single point so the manifold is considerably simpler than the polygon one
+func ComputeCircleContactManifold(circleA, circleB *entities.Circle) ContactManifold {
+	separation := circleB.State.CentroidPosition.Sub(circleA.State.CentroidPosition)
+	distance := separation.Length()
+
+	depth := circleA.Radius + circleB.Radius - distance
+	if depth <= 0 {
+		return ContactManifold{
+			ContactCount: 0,
+		}
+	}
+
+	// If the circles are concentric then any direction is as good as any other
+	collisionNormal := neonMath.Vector2D{X: 0, Y: 1}
+	if distance > equalityTolerance {
+		collisionNormal = separation.Scale(1.0 / distance)
+	}
+	// the contact point lies halfway through the overlapping region
+	contactPoint := circleA.State.CentroidPosition.Add(collisionNormal.Scale(circleA.Radius - depth/2.0))
+
+	return ContactManifold{
+		IncidentFrame:  circleB,
+		ReferenceFrame: circleA,
+
+		MTV:             collisionNormal.Scale(depth),
+		ContactCount:    1,
+		CollisionPoints: []neonMath.Vector2D{contactPoint},
+		ContactDepths:   []float64{depth},
+	}
+}
+
+// ComputeCirclePolygonContactManifold computes the contact manifold between a circle and a polygon, the polygon is always the reference frame
+// so the MTV points from the polygon towards the circle
+func ComputeCirclePolygonContactManifold(circle *entities.Circle, poly *entities.Polygon) ContactManifold {
+	centre := circle.State.CentroidPosition
+	closestPoint, face, inside := poly.ClosestBoundaryPoint(centre)
+	offset := centre.Sub(closestPoint)
+	distance := offset.Length()
+
+	if !inside && distance >= circle.Radius {
+		return ContactManifold{
+			ContactCount: 0,
+		}
+	}
+
+	// When the centre has made it inside the polygon (or sits right on its boundary) the offset is useless as a normal, instead we push out through the closest face
+	collisionNormal, depth := poly.FaceNormal(face), circle.Radius+distance
+	if !inside {
+		depth = circle.Radius - distance
+		if distance > equalityTolerance {
+			collisionNormal = offset.Scale(1.0 / distance)
+		}
+	}
+
+	return ContactManifold{
+		IncidentFrame:  circle,
+		ReferenceFrame: poly,
+
+		ReferenceFace: face,
+
+		MTV:             collisionNormal.Scale(depth),
+		ContactCount:    1,
+		CollisionPoints: []neonMath.Vector2D{closestPoint},
+		ContactDepths:   []float64{depth},
+	}
+}
+
+// DetermineCircleCollision determines if two circles collide, the manifold is only meaningful if they do
+func DetermineCircleCollision(circleA, circleB *entities.Circle) (bool, ContactManifold) {
+	contactManifold := ComputeCircleContactManifold(circleA, circleB)
+	return contactManifold.ContactCount != 0, contactManifold
+}
+
+// DetermineCirclePolygonCollision determines if a circle and a polygon collide
+func DetermineCirclePolygonCollision(circle *entities.Circle, poly *entities.Polygon) (bool, ContactManifold) {
+	contactManifold := ComputeCirclePolygonContactManifold(circle, poly)
+	return contactManifold.ContactCount != 0, contactManifold
+}
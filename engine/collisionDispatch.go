@@ -0,0 +1,129 @@
+package engine
+
+import (
+	"Neon/entities"
+	"Neon/entities/meshes"
+	"math"
+)
+
+// CollisionFunction computes the contact manifold between two bodies, the function may assume that the bodies are of the mesh types it was registered against
+type CollisionFunction func(bodyA, bodyB entities.Body) ContactManifold
+
+// collisionTable maps a pair of mesh types onto the function that knows how to collide them
+// only one ordering of each pair needs to be registered, the other ordering is handled by just swapping the bodies around
+var collisionTable = map[[2]meshes.MeshType]CollisionFunction{
+	{meshes.MeshPolygon, meshes.MeshPolygon}: func(bodyA, bodyB entities.Body) ContactManifold {
+		return ComputePolygonContactManifold(bodyA.(*entities.Polygon), bodyB.(*entities.Polygon))
+	},
+	{meshes.MeshCircle, meshes.MeshCircle}: func(bodyA, bodyB entities.Body) ContactManifold {
+		return ComputeCircleContactManifold(bodyA.(*entities.Circle), bodyB.(*entities.Circle))
+	},
+	{meshes.MeshCircle, meshes.MeshPolygon}: func(bodyA, bodyB entities.Body) ContactManifold {
+		return ComputeCirclePolygonContactManifold(bodyA.(*entities.Circle), bodyB.(*entities.Polygon))
+	},
+	{meshes.MeshCapsule, meshes.MeshCapsule}: func(bodyA, bodyB entities.Body) ContactManifold {
+		return ComputeCapsuleContactManifold(bodyA.(*entities.Capsule), bodyB.(*entities.Capsule))
+	},
+	{meshes.MeshCapsule, meshes.MeshCircle}: func(bodyA, bodyB entities.Body) ContactManifold {
+		return ComputeCapsuleCircleContactManifold(bodyA.(*entities.Capsule), bodyB.(*entities.Circle))
+	},
+	{meshes.MeshCapsule, meshes.MeshPolygon}: func(bodyA, bodyB entities.Body) ContactManifold {
+		return ComputeCapsulePolygonContactManifold(bodyA.(*entities.Capsule), bodyB.(*entities.Polygon))
+	},
+}
+
+// RegisterCollisionFunction registers (or overrides) the function used to collide bodies of meshA against bodies of meshB
+// this is how new colliders get plugged into the engine
+func RegisterCollisionFunction(meshA, meshB meshes.MeshType, collisionFunction CollisionFunction) {
+	collisionTable[[2]meshes.MeshType{meshA, meshB}] = collisionFunction
+}
+
+// ComputeContactManifold computes a contact manifold for any two bodies by looking up the appropriate collision function
+// if the engine has no idea how to collide the two bodies then an empty manifold is returned. Compound bodies can touch with several of their shapes at once,
+// only the deepest of those manifolds is returned here (see ComputeContactManifolds)
+func ComputeContactManifold(bodyA, bodyB entities.Body) ContactManifold {
+	if bodyA.GetMeshType() == meshes.MeshCompound || bodyB.GetMeshType() == meshes.MeshCompound {
+		return deepestManifold(ComputeContactManifolds(bodyA, bodyB))
+	}
+
+	manifold := ContactManifold{ContactCount: 0}
+	if collisionFunction, ok := collisionTable[[2]meshes.MeshType{bodyA.GetMeshType(), bodyB.GetMeshType()}]; ok {
+		manifold = collisionFunction(bodyA, bodyB)
+	} else if collisionFunction, ok := collisionTable[[2]meshes.MeshType{bodyB.GetMeshType(), bodyA.GetMeshType()}]; ok {
+		// note that the manifold already records which body is the reference and which is the incident so swapping them is perfectly safe
+		manifold = collisionFunction(bodyB, bodyA)
+	}
+
+	manifold.IncidentShape, manifold.ReferenceShape = manifold.IncidentFrame, manifold.ReferenceFrame
+	return manifold
+}
+
+// ComputeContactManifolds computes a contact manifold for every pair of shapes that are touching between the two bodies
+// this is just the one manifold unless one of the bodies is a compound, the frames of each manifold are always the bodies themselves
+func ComputeContactManifolds(bodyA, bodyB entities.Body) []ContactManifold {
+	owners := make(map[entities.Body]entities.Body)
+	shapesA, shapesB := compoundShapes(bodyA, owners), compoundShapes(bodyB, owners)
+
+	manifolds := []ContactManifold{}
+	for _, shapeA := range shapesA {
+		for _, shapeB := range shapesB {
+			if !shapeA.GetBoundingBox().Overlaps(shapeB.GetBoundingBox()) {
+				continue
+			}
+
+			manifold := ComputeContactManifold(shapeA, shapeB)
+			if manifold.ContactCount == 0 {
+				continue
+			}
+			manifold.IncidentFrame, manifold.ReferenceFrame = owners[manifold.IncidentFrame], owners[manifold.ReferenceFrame]
+			manifolds = append(manifolds, manifold)
+		}
+	}
+	return manifolds
+}
+
+// compoundShapes returns the shapes of a compound body (or just the body if it isnt a compound), owners records which body each shape belongs to
+func compoundShapes(body entities.Body, owners map[entities.Body]entities.Body) []entities.Body {
+	shapes := []entities.Body{body}
+	if compound, ok := body.(*entities.Compound); ok {
+		shapes = compound.Shapes()
+	}
+
+	for _, shape := range shapes {
+		owners[shape] = body
+	}
+	return shapes
+}
+
+// deepestManifold picks out the manifold with the deepest contact, an empty manifold is returned if there arent any
+func deepestManifold(manifolds []ContactManifold) ContactManifold {
+	deepest, depth := ContactManifold{ContactCount: 0}, math.Inf(-1)
+	for _, manifold := range manifolds {
+		for _, contactDepth := range manifold.ContactDepths {
+			if contactDepth > depth {
+				deepest, depth = manifold, contactDepth
+			}
+		}
+	}
+	return deepest
+}
+
+// shapes returns the shapes that were hit in the manifold (reference first), manifolds that were built by hand might not have any so the frames are used instead
+func (manifold ContactManifold) shapes() (entities.Body, entities.Body) {
+	reference, incident := manifold.ReferenceShape, manifold.IncidentShape
+	if reference == nil {
+		reference = manifold.ReferenceFrame
+	}
+	if incident == nil {
+		incident = manifold.IncidentFrame
+	}
+	return reference, incident
+}
+
+// DetermineCollision determines if two bodies collide and computes the contact manifold between them
+// For compound bodies this is only the deepest manifold (as with ComputeContactManifold), which is enough to tell if they overlap but misses any
+// other shapes that are touching, use ComputeContactManifolds if every contact is needed
+func DetermineCollision(bodyA, bodyB entities.Body) (bool, ContactManifold) {
+	contactManifold := ComputeContactManifold(bodyA, bodyB)
+	return contactManifold.ContactCount != 0, contactManifold
+}
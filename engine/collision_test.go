@@ -3,6 +3,7 @@ package engine
 import (
 	neonMath "Neon/engine/math"
 	"Neon/entities"
+	"math"
 	"testing"
 )
 
@@ -20,3 +21,257 @@ func TestManifoldGeneration(t *testing.T) {
 func TestClipping(t *testing.T) {
 
 }
+
+func TestCircleManifoldGeneration(t *testing.T) {
+	circleA := entities.NewCircle(neonMath.Vector2D{X: 0, Y: 0}, 50)
+	circleB := entities.NewCircle(neonMath.Vector2D{X: 90, Y: 0}, 50)
+
+	manifold := ComputeCircleContactManifold(&circleA, &circleB)
+	if manifold.ContactCount != 1 {
+		t.Fatalf("expected a single contact point, got %d", manifold.ContactCount)
+	}
+	if manifold.MTV != (neonMath.Vector2D{X: 10, Y: 0}) {
+		t.Errorf("expected an MTV of (10, 0), got %v", manifold.MTV)
+	}
+	if manifold.CollisionPoints[0] != (neonMath.Vector2D{X: 45, Y: 0}) {
+		t.Errorf("expected the contact to be at (45, 0), got %v", manifold.CollisionPoints[0])
+	}
+
+	circleB.State.CentroidPosition = neonMath.Vector2D{X: 101, Y: 0}
+	if collides, _ := DetermineCircleCollision(&circleA, &circleB); collides {
+		t.Errorf("circles that are apart should not collide")
+	}
+}
+
+func TestCirclePolygonManifoldGeneration(t *testing.T) {
+	poly := entities.NewPolygon([]neonMath.Vector2D{{X: 0, Y: 100}, {X: 100, Y: 100}, {X: 100, Y: 0}, {X: 0, Y: 0}})
+
+	// resting on the top face
+	circle := entities.NewCircle(neonMath.Vector2D{X: 50, Y: 120}, 30)
+	manifold := ComputeCirclePolygonContactManifold(&circle, &poly)
+	if manifold.ContactCount != 1 {
+		t.Fatalf("expected a single contact point, got %d", manifold.ContactCount)
+	}
+	if manifold.MTV.Sub(neonMath.Vector2D{X: 0, Y: 10}).Length() > equalityTolerance {
+		t.Errorf("expected an MTV of (0, 10), got %v", manifold.MTV)
+	}
+	if manifold.CollisionPoints[0].Sub(neonMath.Vector2D{X: 50, Y: 100}).Length() > equalityTolerance {
+		t.Errorf("expected the contact to be at (50, 100), got %v", manifold.CollisionPoints[0])
+	}
+
+	// centre has already passed through the right face
+	circle.State.CentroidPosition = neonMath.Vector2D{X: 95, Y: 50}
+	manifold = ComputeCirclePolygonContactManifold(&circle, &poly)
+	if manifold.MTV.Sub(neonMath.Vector2D{X: 35, Y: 0}).Length() > equalityTolerance {
+		t.Errorf("expected an MTV of (35, 0), got %v", manifold.MTV)
+	}
+
+	// touching a corner from the outside
+	circle.State.CentroidPosition = neonMath.Vector2D{X: 120, Y: 120}
+	if collides, _ := DetermineCirclePolygonCollision(&circle, &poly); !collides {
+		t.Errorf("expected the circle to collide with the corner")
+	}
+
+	// dead in the middle every face is just as close, the same one should be picked every time
+	circle.State.CentroidPosition = neonMath.Vector2D{X: 50, Y: 50}
+	first := ComputeCirclePolygonContactManifold(&circle, &poly)
+	for i := 0; i < 20; i++ {
+		if manifold := ComputeCirclePolygonContactManifold(&circle, &poly); manifold.MTV != first.MTV {
+			t.Fatalf("the same circle was pushed out along %v and then %v", first.MTV, manifold.MTV)
+		}
+	}
+}
+
+func TestCapsuleManifoldGeneration(t *testing.T) {
+	ground := entities.NewPolygon([]neonMath.Vector2D{{X: 0, Y: 100}, {X: 300, Y: 100}, {X: 300, Y: 0}, {X: 0, Y: 0}})
+
+	// a capsule lying flat on the ground should be supported at both ends
+	capsule := entities.NewCapsule(neonMath.Vector2D{X: 50, Y: 115}, neonMath.Vector2D{X: 150, Y: 115}, 20)
+	manifold := ComputeCapsulePolygonContactManifold(&capsule, &ground)
+	if manifold.ContactCount != 2 {
+		t.Fatalf("expected two contact points, got %d", manifold.ContactCount)
+	}
+	if manifold.MTV.Sub(neonMath.Vector2D{X: 0, Y: 5}).Length() > equalityTolerance {
+		t.Errorf("expected an MTV of (0, 5), got %v", manifold.MTV)
+	}
+	for i, depth := range manifold.ContactDepths {
+		if math.Abs(depth-5) > equalityTolerance {
+			t.Errorf("expected contact %d to have a depth of 5, got %f", i, depth)
+		}
+	}
+
+	// a short capsule in the middle of a square is just as deep into every face, the same one should be picked every time
+	square := entities.NewPolygon([]neonMath.Vector2D{{X: 0, Y: 100}, {X: 100, Y: 100}, {X: 100, Y: 0}, {X: 0, Y: 0}})
+	stub := entities.NewCapsule(neonMath.Vector2D{X: 50, Y: 50}, neonMath.Vector2D{X: 50, Y: 50}, 20)
+	first := ComputeCapsulePolygonContactManifold(&stub, &square)
+	for i := 0; i < 20; i++ {
+		if manifold := ComputeCapsulePolygonContactManifold(&stub, &square); manifold.MTV != first.MTV {
+			t.Fatalf("the same capsule was pushed out along %v and then %v", first.MTV, manifold.MTV)
+		}
+	}
+
+	// standing upright it should only touch at the bottom
+	capsule = entities.NewCapsule(neonMath.Vector2D{X: 50, Y: 115}, neonMath.Vector2D{X: 50, Y: 215}, 20)
+	manifold = ComputeCapsulePolygonContactManifold(&capsule, &ground)
+	if manifold.ContactCount != 1 {
+		t.Errorf("expected a single contact point, got %d", manifold.ContactCount)
+	}
+
+	// two parallel capsules on top of each other
+	other := entities.NewCapsule(neonMath.Vector2D{X: 100, Y: 150}, neonMath.Vector2D{X: 200, Y: 150}, 20)
+	capsule = entities.NewCapsule(neonMath.Vector2D{X: 50, Y: 115}, neonMath.Vector2D{X: 150, Y: 115}, 20)
+	manifold = ComputeCapsuleContactManifold(&capsule, &other)
+	if manifold.ContactCount != 2 {
+		t.Errorf("expected two contact points, got %d", manifold.ContactCount)
+	}
+	if manifold.MTV.Sub(neonMath.Vector2D{X: 0, Y: 5}).Length() > equalityTolerance {
+		t.Errorf("expected an MTV of (0, 5), got %v", manifold.MTV)
+	}
+
+	circle := entities.NewCircle(neonMath.Vector2D{X: 220, Y: 115}, 40)
+	if collides, _ := DetermineCapsuleCircleCollision(&capsule, &circle); collides {
+		t.Errorf("the circle should be clear of the end of the capsule")
+	}
+}
+
+func TestCollisionDispatch(t *testing.T) {
+	poly := entities.NewPolygon([]neonMath.Vector2D{{X: 0, Y: 100}, {X: 100, Y: 100}, {X: 100, Y: 0}, {X: 0, Y: 0}})
+	circle := entities.NewCircle(neonMath.Vector2D{X: 50, Y: 120}, 30)
+
+	// the table only knows about circle-polygon so this ordering has to be swapped around internally
+	collides, manifold := DetermineCollision(&poly, &circle)
+	if !collides {
+		t.Fatalf("expected the polygon and circle to collide")
+	}
+	if manifold.ReferenceFrame != entities.Body(&poly) || manifold.IncidentFrame != entities.Body(&circle) {
+		t.Errorf("expected the polygon to be the reference frame")
+	}
+	if manifold.MTV.Y <= 0 {
+		t.Errorf("expected the MTV to point from the polygon towards the circle, got %v", manifold.MTV)
+	}
+}
+
+func TestCollisionFiltering(t *testing.T) {
+	player := entities.NewCircle(neonMath.Vector2D{X: 0}, 20)
+	bullet := entities.NewCircle(neonMath.Vector2D{X: 10}, 5)
+	debrisA := entities.NewCircle(neonMath.Vector2D{X: 100}, 20)
+	debrisB := entities.NewCircle(neonMath.Vector2D{X: 110}, 20)
+
+	// the bullet ignores the player it was fired from and debris ignores other debris
+	player.State.Filter = entities.CollisionFilter{Category: 0x0002, Mask: 0xFFFF &^ 0x0004}
+	bullet.State.Filter = entities.CollisionFilter{Category: 0x0004, Mask: 0xFFFF}
+	debrisA.State.Filter = entities.CollisionFilter{Category: 0x0001, Mask: 0xFFFF, Group: -1}
+	debrisB.State.Filter = debrisA.State.Filter
+
+	manager := NewPhysicsManager()
+	manager.BeginTracking(&player, &bullet, &debrisA, &debrisB)
+	if manifolds := manager.DetectCollisions(); len(manifolds) != 0 {
+		t.Errorf("every pair should have been filtered out, got %v collisions", len(manifolds))
+	}
+
+	// positive groups always collide, even though the masks say otherwise
+	player.State.Filter.Group, bullet.State.Filter.Group = 1, 1
+	if manifolds := manager.DetectCollisions(); len(manifolds) != 1 {
+		t.Errorf("the player and bullet should have collided, got %v collisions", len(manifolds))
+	}
+
+	// an unset filter collides with everything, just like the default one
+	player.State.Filter, bullet.State.Filter = entities.CollisionFilter{}, entities.DefaultCollisionFilter
+	if manifolds := manager.DetectCollisions(); len(manifolds) != 1 {
+		t.Errorf("a zero valued filter should behave like the default filter, got %v collisions", len(manifolds))
+	}
+
+	// the user predicate gets the final say
+	manager.SetContactFilter(func(bodyA, bodyB entities.Body) bool { return false })
+	if manifolds := manager.DetectCollisions(); len(manifolds) != 0 {
+		t.Errorf("the predicate should have vetoed every pair, got %v collisions", len(manifolds))
+	}
+}
+
+func TestContactEvents(t *testing.T) {
+	ground := groundFixture()
+	ground.State.Material.Restitution = 0
+	ball := entities.NewCircle(neonMath.Vector2D{Y: 9}, 10)
+	ball.State.Material.Restitution = 0
+
+	begins, persists, ends, normalImpulse := 0, 0, 0, 0.0
+	manager := NewPhysicsManager()
+	manager.SetGravity(neonMath.Vector2D{Y: -9.8})
+	manager.BeginTracking(ground, &ball)
+	manager.AddContactListener(ContactListener{
+		BeginContact:   func(manifold ContactManifold) { begins++ },
+		PersistContact: func(manifold ContactManifold) { persists++ },
+		EndContact:     func(bodyA, bodyB entities.Body) { ends++ },
+		PostSolve: func(manifold ContactManifold, impulses []ContactImpulse) {
+			for _, impulse := range impulses {
+				normalImpulse += impulse.Normal
+			}
+		},
+	})
+
+	for i := 0; i < 10; i++ {
+		manager.NextTimeStep(1.0 / 60.0)
+	}
+	if begins != 1 || persists != 9 || ends != 0 || normalImpulse <= 0 {
+		t.Errorf("expected 1 begin, 9 persists and a positive normal impulse, got %v begins, %v persists, %v ends and an impulse of %v", begins, persists, ends, normalImpulse)
+	}
+
+	// disabling the contact lets the ball fall through the ground, just like a one way platform
+	manager.AddContactListener(ContactListener{PreSolve: func(contact *Contact) { contact.Enabled = false }})
+	for i := 0; i < 30; i++ {
+		manager.NextTimeStep(1.0 / 60.0)
+	}
+	if ends != 1 || ball.State.CentroidPosition.Y > -10 {
+		t.Errorf("expected the ball to fall through the ground, it is at %v and the contact ended %v times", ball.State.CentroidPosition, ends)
+	}
+}
+
+func TestCompoundCollisions(t *testing.T) {
+	ground := groundFixture()
+	ground.State.Material = entities.Material{StaticFriction: 0.6, DynamicFriction: 0.4}
+
+	// a table, two legs with a top across them, dropped just above the ground
+	legs := []*entities.Polygon{}
+	for _, x := range []float64{-100, 100} {
+		leg := entities.NewPolygon([]neonMath.Vector2D{{X: x - 20, Y: 50}, {X: x + 20, Y: 50}, {X: x + 20, Y: 10}, {X: x - 20, Y: 10}})
+		leg.State.Material = entities.Material{StaticFriction: 0.6, DynamicFriction: 0.4, Density: 1.0}
+		entities.ComputeMass(&leg)
+		legs = append(legs, &leg)
+	}
+	top := entities.NewPolygon([]neonMath.Vector2D{{X: -120, Y: 70}, {X: 120, Y: 70}, {X: 120, Y: 50}, {X: -120, Y: 50}})
+	top.State.Material = entities.Material{StaticFriction: 0.6, DynamicFriction: 0.4, Density: 1.0}
+	entities.ComputeMass(&top)
+	table := entities.NewCompound(legs[0], legs[1], &top)
+
+	manager := NewPhysicsManager()
+	manager.SetGravity(neonMath.Vector2D{Y: -9.8})
+	manager.BeginTracking(ground, &table)
+	for i := 0; i < 60; i++ {
+		manager.NextTimeStep(1.0 / 60.0)
+	}
+
+	// both legs should be standing on the ground, each with its own manifold
+	manifolds := ComputeContactManifolds(&table, ground)
+	hit := make(map[entities.Body]bool)
+	for _, manifold := range manifolds {
+		if manifold.IncidentFrame != &table && manifold.ReferenceFrame != &table {
+			t.Fatalf("the frames of the manifold should be the table and the ground")
+		}
+		shape := manifold.IncidentShape
+		if manifold.ReferenceFrame == &table {
+			shape = manifold.ReferenceShape
+		}
+		hit[shape] = true
+	}
+	if len(manifolds) != 2 || !hit[legs[0]] || !hit[legs[1]] {
+		t.Errorf("expected both legs of the table to be touching the ground, got %v manifolds", len(manifolds))
+	}
+	if math.Abs(table.State.Angle) > 1e-3 || legs[0].GetBoundingBox().Min.Y < -manager.solverConfig.Slop {
+		t.Errorf("the table should be standing upright on the ground, its at an angle of %v with its legs at %v", table.State.Angle, legs[0].GetBoundingBox().Min)
+	}
+
+	// everything else still only reports the deepest manifold
+	if collides, manifold := DetermineCollision(&table, ground); !collides || manifold.ContactCount == 0 {
+		t.Errorf("expected the table to be colliding with the ground")
+	}
+}
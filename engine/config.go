@@ -1,4 +1,30 @@
 package engine
 
+import (
+	neonMath "Neon/engine/math"
+	"math"
+)
+
 // equalityTolerance is a floating point "margin of error" for determining if two values are equal or not
 const equalityTolerance float64 = 0.0084
+
+// referenceFaceTolerance is how much better aligned with the collision normal the face of B has to be before it is chosen as the reference face over A
+const referenceFaceTolerance float64 = 0.001
+
+// toiTolerance is how far (in world units) a bullet is allowed to end up inside whatever it hits, its never pushed past the first time of impact by more than this
+const toiTolerance float64 = 0.005 * neonMath.Metre
+
+// aabbMargin is how far (in world units) the bounding boxes within the broadphase are fattened by
+const aabbMargin float64 = 0.1 * neonMath.Metre
+
+// maxJointCorrection is the furthest (in metres) a joint will move its bodies within a single position iteration, this stops large errors from exploding
+const maxJointCorrection float64 = 0.2
+
+// maxJointAngularCorrection is the furthest (in radians) a joint will rotate its bodies within a single position iteration
+const maxJointAngularCorrection float64 = 8.0 * math.Pi / 180.0
+
+// angularSlop is how far (in radians) a joint is allowed to rotate past its limits, this stops the limits from flickering on and off
+const angularSlop float64 = 2.0 * math.Pi / 180.0
+
+// linearSlop is how far (in metres) a joint is allowed to slide past its limits
+const linearSlop float64 = 0.005
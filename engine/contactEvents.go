@@ -0,0 +1,102 @@
+package engine
+
+import "Neon/entities"
+
+/*
+	Contact events let the outside world react to contacts as they happen, pairs of bodies are tracked across timesteps so that the start
+	of a contact can be told apart from one that is still ongoing. Bodies that are resting against each other (eg. they have both fallen asleep)
+	are never collided, so their contact is just assumed to persist until one of them starts moving again
+*/
+
+// ContactListener is notified about the contacts between bodies, any of the callbacks can be left nil
+type ContactListener struct {
+	BeginContact   func(manifold ContactManifold)   // the first timestep that two bodies touch
+	PersistContact func(manifold ContactManifold)   // every timestep after that where they are still touching
+	EndContact     func(bodyA, bodyB entities.Body) // the first timestep that they stop touching
+
+	// PreSolve is invoked before a contact is solved, it can disable the contact (eg. for one way platforms) or change its material properties
+	PreSolve func(contact *Contact)
+	// PostSolve is invoked after a contact is solved with the impulse that was applied at each of its contact points
+	PostSolve func(manifold ContactManifold, impulses []ContactImpulse)
+}
+
+// AddContactListener registers a listener for contact events
+func (receiver *PhysicsManager) AddContactListener(listener ContactListener) {
+	receiver.contactListeners = append(receiver.contactListeners, listener)
+}
+
+// contactPair identifies the pair of frames in a manifold, if the pair is already being tracked the existing ordering is reused
+func (receiver PhysicsManager) contactPair(manifold ContactManifold) BodyPair {
+	pair := BodyPair{A: manifold.ReferenceFrame, B: manifold.IncidentFrame}
+	if reversed := (BodyPair{A: pair.B, B: pair.A}); receiver.contactPairs[reversed] {
+		return reversed
+	}
+	return pair
+}
+
+// updateContactPairs compares the manifolds against the pairs that were touching last time and fires the begin, persist and end events accordingly
+func (receiver PhysicsManager) updateContactPairs(manifolds []ContactManifold) {
+	current := make(map[BodyPair]bool)
+	for _, manifold := range manifolds {
+		if manifold.isSensor() {
+			continue
+		}
+
+		// a compound body can touch the same body with several of its shapes, the events are only fired once for the pair of bodies
+		pair := receiver.contactPair(manifold)
+		if current[pair] {
+			continue
+		}
+		current[pair] = true
+		for _, listener := range receiver.contactListeners {
+			if receiver.contactPairs[pair] && listener.PersistContact != nil {
+				listener.PersistContact(manifold)
+			} else if !receiver.contactPairs[pair] && listener.BeginContact != nil {
+				listener.BeginContact(manifold)
+			}
+		}
+		receiver.contactPairs[pair] = true
+	}
+
+	for pair := range receiver.contactPairs {
+		if !current[pair] && (isActive(pair.A) || isActive(pair.B)) {
+			receiver.endContact(pair)
+		}
+	}
+}
+
+// removeContactPairs ends every contact involving the body, this is used when the body stops being tracked
+func (receiver PhysicsManager) removeContactPairs(body entities.Body) {
+	for pair := range receiver.contactPairs {
+		if pair.A == body || pair.B == body {
+			receiver.endContact(pair)
+		}
+	}
+}
+
+func (receiver PhysicsManager) endContact(pair BodyPair) {
+	delete(receiver.contactPairs, pair)
+	for _, listener := range receiver.contactListeners {
+		if listener.EndContact != nil {
+			listener.EndContact(pair.A, pair.B)
+		}
+	}
+}
+
+// preSolve runs every pre-solve hook on the contact
+func (receiver PhysicsManager) preSolve(contact *Contact) {
+	for _, listener := range receiver.contactListeners {
+		if listener.PreSolve != nil {
+			listener.PreSolve(contact)
+		}
+	}
+}
+
+// postSolve runs every post-solve hook on the manifold
+func (receiver PhysicsManager) postSolve(manifold ContactManifold, impulses []ContactImpulse) {
+	for _, listener := range receiver.contactListeners {
+		if listener.PostSolve != nil {
+			listener.PostSolve(manifold, impulses)
+		}
+	}
+}
@@ -1,3 +1,293 @@
 package engine
 
+import (
+	neonMath "Neon/engine/math"
+	"Neon/entities"
+)
+
 // Implementation of common and elementary datastructures eg... Quadtrees :)
+
+// aabbNode is a single node within the AABB tree, leaves hold bodies while internal nodes just hold the union of their children's boxes
+type aabbNode struct {
+	box                 neonMath.AABB
+	parent, left, right *aabbNode
+	height              int
+
+	// Only defined for leaves
+	body entities.Body
+	id   int
+}
+
+func (node *aabbNode) isLeaf() bool {
+	return node.left == nil
+}
+
+// AABBTree is a dynamic bounding volume hierarchy, every body is stored in a leaf with a "fattened" bounding box
+// since the boxes are fat a body can move around a bit before its leaf has to be reinserted, which makes updating the tree cheap
+type AABBTree struct {
+	root   *aabbNode
+	leaves []*aabbNode
+	lookup map[entities.Body]*aabbNode
+	margin float64
+
+	// internal var for assigning leaf IDs
+	nextID int
+}
+
+// NewAABBTree creates an empty tree, margin is how much each box is fattened by (in world units)
+func NewAABBTree(margin float64) *AABBTree {
+	return &AABBTree{
+		lookup: make(map[entities.Body]*aabbNode),
+		margin: margin,
+	}
+}
+
+// Insert adds a body to the tree
+func (tree *AABBTree) Insert(body entities.Body) {
+	if _, exists := tree.lookup[body]; exists {
+		return
+	}
+
+	leaf := &aabbNode{
+		box:  body.GetBoundingBox().Fatten(tree.margin),
+		body: body,
+		id:   tree.nextID,
+	}
+	tree.nextID++
+
+	tree.lookup[body] = leaf
+	tree.leaves = append(tree.leaves, leaf)
+	tree.insertLeaf(leaf)
+}
+
+// Remove removes a body from the tree
+func (tree *AABBTree) Remove(body entities.Body) {
+	leaf, exists := tree.lookup[body]
+	if !exists {
+		return
+	}
+
+	tree.removeLeaf(leaf)
+	delete(tree.lookup, body)
+	for i, l := range tree.leaves {
+		if l == leaf {
+			tree.leaves = append(tree.leaves[:i], tree.leaves[i+1:]...)
+			break
+		}
+	}
+}
+
+// Update reinserts every body that has escaped its fattened box
+func (tree *AABBTree) Update() {
+	for _, leaf := range tree.leaves {
+		box := leaf.body.GetBoundingBox()
+		if leaf.box.Contains(box) {
+			continue
+		}
+
+		tree.removeLeaf(leaf)
+		leaf.box = box.Fatten(tree.margin)
+		tree.insertLeaf(leaf)
+	}
+}
+
+// ComputePairs returns every pair of bodies whose fattened boxes overlap, each pair is only reported once
+func (tree *AABBTree) ComputePairs() []BodyPair {
+	pairs := []BodyPair{}
+	for _, leaf := range tree.leaves {
+		tree.query(leaf.box, func(other *aabbNode) {
+			// the ID check makes sure we only report each pair once (and never pair a body with itself)
+			if other.id > leaf.id {
+				pairs = append(pairs, BodyPair{A: leaf.body, B: other.body})
+			}
+		})
+	}
+	return pairs
+}
+
+// QueryAABB returns every body whose bounding box overlaps the provided box
+func (tree *AABBTree) QueryAABB(box neonMath.AABB) []entities.Body {
+	bodies := []entities.Body{}
+	tree.query(box, func(leaf *aabbNode) {
+		if leaf.body.GetBoundingBox().Overlaps(box) {
+			bodies = append(bodies, leaf.body)
+		}
+	})
+	return bodies
+}
+
+// query invokes the callback on every leaf whose fattened box overlaps the provided box
+func (tree *AABBTree) query(box neonMath.AABB, callback func(leaf *aabbNode)) {
+	if tree.root == nil {
+		return
+	}
+
+	stack := []*aabbNode{tree.root}
+	for len(stack) != 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		if !node.box.Overlaps(box) {
+			continue
+		}
+		if node.isLeaf() {
+			callback(node)
+		} else {
+			stack = append(stack, node.left, node.right)
+		}
+	}
+}
+
+// insertLeaf finds the best sibling for the leaf (the one that grows the tree the least) and attaches it there
+func (tree *AABBTree) insertLeaf(leaf *aabbNode) {
+	leaf.parent = nil
+	if tree.root == nil {
+		tree.root = leaf
+		return
+	}
+
+	// Descend the tree using the perimeter of the boxes as a cost heuristic
+	sibling := tree.root
+	for !sibling.isLeaf() {
+		perimeter := sibling.box.Perimeter()
+		combinedPerimeter := sibling.box.Union(leaf.box).Perimeter()
+
+		// cost of making a new parent for the sibling and the leaf, and the cost pushed down onto the children if we descend further
+		cost := 2.0 * combinedPerimeter
+		inheritanceCost := 2.0 * (combinedPerimeter - perimeter)
+
+		childCost := func(child *aabbNode) float64 {
+			if child.isLeaf() {
+				return child.box.Union(leaf.box).Perimeter() + inheritanceCost
+			}
+			return child.box.Union(leaf.box).Perimeter() - child.box.Perimeter() + inheritanceCost
+		}
+		costLeft, costRight := childCost(sibling.left), childCost(sibling.right)
+
+		if cost < costLeft && cost < costRight {
+			break
+		}
+		if costLeft < costRight {
+			sibling = sibling.left
+		} else {
+			sibling = sibling.right
+		}
+	}
+
+	// Create a new parent for the sibling and the leaf
+	oldParent := sibling.parent
+	newParent := &aabbNode{
+		box:    sibling.box.Union(leaf.box),
+		parent: oldParent,
+		left:   sibling,
+		right:  leaf,
+		height: sibling.height + 1,
+	}
+	sibling.parent, leaf.parent = newParent, newParent
+
+	if oldParent == nil {
+		tree.root = newParent
+	} else {
+		oldParent.replaceChild(sibling, newParent)
+	}
+
+	tree.refit(newParent.parent)
+}
+
+// removeLeaf detaches a leaf from the tree, its sibling takes the place of their parent
+func (tree *AABBTree) removeLeaf(leaf *aabbNode) {
+	if leaf == tree.root {
+		tree.root = nil
+		return
+	}
+
+	parent := leaf.parent
+	sibling := parent.left
+	if sibling == leaf {
+		sibling = parent.right
+	}
+
+	grandParent := parent.parent
+	sibling.parent = grandParent
+	leaf.parent = nil
+	if grandParent == nil {
+		tree.root = sibling
+		return
+	}
+
+	grandParent.replaceChild(parent, sibling)
+	tree.refit(grandParent)
+}
+
+// refit walks up the tree from node rebalancing it and recomputing the boxes and heights
+func (tree *AABBTree) refit(node *aabbNode) {
+	for node != nil {
+		node = tree.balance(node)
+		node.box = node.left.box.Union(node.right.box)
+		node.height = 1 + maxInt(node.left.height, node.right.height)
+
+		node = node.parent
+	}
+}
+
+// balance performs a tree rotation on node if it is imbalanced, it returns the node that now sits where node used to
+func (tree *AABBTree) balance(a *aabbNode) *aabbNode {
+	if a.isLeaf() || a.height < 2 {
+		return a
+	}
+
+	b, c := a.left, a.right
+	switch balance := c.height - b.height; {
+	case balance > 1:
+		// c is too tall so it gets rotated up
+		tree.rotateUp(a, c, b, true)
+		return c
+	case balance < -1:
+		// b is too tall so it gets rotated up
+		tree.rotateUp(a, b, c, false)
+		return b
+	}
+	return a
+}
+
+// rotateUp promotes the child "up" of a into a's place, "other" is a's remaining child
+// the shorter of up's children is handed down to a, the taller one stays with up
+func (tree *AABBTree) rotateUp(a, up, other *aabbNode, upIsRight bool) {
+	f, g := up.left, up.right
+
+	up.left = a
+	up.parent = a.parent
+	a.parent = up
+	if up.parent == nil {
+		tree.root = up
+	} else {
+		up.parent.replaceChild(a, up)
+	}
+
+	taller, shorter := f, g
+	if g.height > f.height {
+		taller, shorter = g, f
+	}
+
+	up.right = taller
+	shorter.parent = a
+	if upIsRight {
+		a.right = shorter
+	} else {
+		a.left = shorter
+	}
+
+	a.box = other.box.Union(shorter.box)
+	a.height = 1 + maxInt(other.height, shorter.height)
+	up.box = a.box.Union(taller.box)
+	up.height = 1 + maxInt(a.height, taller.height)
+}
+
+// replaceChild swaps out one child of a node for another node
+func (node *aabbNode) replaceChild(oldChild, newChild *aabbNode) {
+	if node.left == oldChild {
+		node.left = newChild
+	} else {
+		node.right = newChild
+	}
+}
@@ -0,0 +1,140 @@
+package engine
+
+import (
+	neonMath "Neon/engine/math"
+	"Neon/entities"
+	"math"
+)
+
+// DistanceJoint keeps the anchors of two bodies a fixed distance apart, if it is given a stiffness then it acts like a spring instead
+type DistanceJoint struct {
+	JointBodies
+
+	Length    float64 // rest length in world units
+	Stiffness float64 // spring stiffness in N/m, if this is 0 the joint is rigid
+	Damping   float64 // spring damping in Ns/m
+
+	a, b              jointBody
+	axis              neonMath.Vector2D
+	mass, bias, gamma float64
+	impulse           float64
+}
+
+// NewDistanceJoint creates a rigid distance joint between the anchors (relative to each centroid), its length is however far apart the anchors currently are
+func NewDistanceJoint(bodyA, bodyB entities.Body, localAnchorA, localAnchorB neonMath.Vector2D) *DistanceJoint {
+	joint := &DistanceJoint{JointBodies: JointBodies{BodyA: bodyA, BodyB: bodyB, LocalAnchorA: localAnchorA, LocalAnchorB: localAnchorB}}
+	anchorA, anchorB := joint.WorldAnchors()
+	joint.Length = anchorB.Sub(anchorA).Length()
+
+	return joint
+}
+
+func (joint *DistanceJoint) prepare(config SolverConfig, dt float64) {
+	joint.a, joint.b = joint.solverBodies()
+
+	var length float64
+	joint.axis, length = jointAxis(joint.a, joint.b)
+	error := length - joint.Length/neonMath.Metre
+
+	// rigid joints are put back into place by the position solve, so only springs need a bias here
+	invMass := joint.a.inverseMass(joint.axis) + joint.b.inverseMass(joint.axis)
+	joint.gamma, joint.bias = 0, 0
+	if joint.Stiffness > 0 {
+		joint.gamma, joint.bias = softConstraint(joint.Stiffness, joint.Damping, error, dt)
+		invMass += joint.gamma
+	}
+	joint.mass = inverse(invMass)
+
+	if !config.WarmStarting {
+		joint.impulse = 0
+	}
+	applyJointImpulse(joint.a, joint.b, joint.axis.Scale(joint.impulse))
+}
+
+func (joint *DistanceJoint) solveVelocities() {
+	separationVelocity := joint.b.velocity().Sub(joint.a.velocity()).Dot(joint.axis)
+	impulse := -joint.mass * (separationVelocity + joint.bias + joint.gamma*joint.impulse)
+	joint.impulse += impulse
+
+	applyJointImpulse(joint.a, joint.b, joint.axis.Scale(impulse))
+}
+
+func (joint *DistanceJoint) solvePositions() {
+	// springs are meant to stretch
+	if joint.Stiffness > 0 {
+		return
+	}
+
+	a, b := joint.solverBodies()
+	axis, length := jointAxis(a, b)
+	error := clamp(length-joint.Length/neonMath.Metre, -maxJointCorrection, maxJointCorrection)
+
+	impulse := -inverse(a.inverseMass(axis)+b.inverseMass(axis)) * error
+	applyJointPositionImpulse(a, b, axis.Scale(impulse))
+}
+
+// RopeJoint stops the anchors of two bodies getting any further apart than MaxLength, they are free to move closer together
+type RopeJoint struct {
+	JointBodies
+
+	MaxLength float64 // in world units
+
+	a, b       jointBody
+	axis       neonMath.Vector2D
+	mass, bias float64
+	impulse    float64
+}
+
+// NewRopeJoint creates a rope between the anchors (relative to each centroid)
+func NewRopeJoint(bodyA, bodyB entities.Body, localAnchorA, localAnchorB neonMath.Vector2D, maxLength float64) *RopeJoint {
+	return &RopeJoint{
+		JointBodies: JointBodies{BodyA: bodyA, BodyB: bodyB, LocalAnchorA: localAnchorA, LocalAnchorB: localAnchorB},
+		MaxLength:   maxLength,
+	}
+}
+
+func (joint *RopeJoint) prepare(config SolverConfig, dt float64) {
+	joint.a, joint.b = joint.solverBodies()
+
+	var length float64
+	joint.axis, length = jointAxis(joint.a, joint.b)
+	joint.mass = inverse(joint.a.inverseMass(joint.axis) + joint.b.inverseMass(joint.axis))
+
+	// while the rope is slack the bodies are allowed to close whatever gap is left within this timestep, a stretched rope is fixed by the position solve
+	joint.bias = math.Min(length-joint.MaxLength/neonMath.Metre, 0) / dt
+
+	if !config.WarmStarting {
+		joint.impulse = 0
+	}
+	applyJointImpulse(joint.a, joint.b, joint.axis.Scale(joint.impulse))
+}
+
+func (joint *RopeJoint) solveVelocities() {
+	separationVelocity := joint.b.velocity().Sub(joint.a.velocity()).Dot(joint.axis)
+	impulse := -joint.mass * (separationVelocity + joint.bias)
+
+	// ropes can only ever pull
+	accumulated := math.Min(joint.impulse+impulse, 0)
+	impulse, joint.impulse = accumulated-joint.impulse, accumulated
+
+	applyJointImpulse(joint.a, joint.b, joint.axis.Scale(impulse))
+}
+
+func (joint *RopeJoint) solvePositions() {
+	a, b := joint.solverBodies()
+	axis, length := jointAxis(a, b)
+	error := clamp(length-joint.MaxLength/neonMath.Metre, 0, maxJointCorrection)
+
+	impulse := -inverse(a.inverseMass(axis)+b.inverseMass(axis)) * error
+	applyJointPositionImpulse(a, b, axis.Scale(impulse))
+}
+
+// jointAxis returns the direction from anchor a to anchor b along with the distance between them (in metres)
+func jointAxis(a, b jointBody) (neonMath.Vector2D, float64) {
+	separation := b.anchor().Sub(a.anchor())
+	length := separation.Length()
+	if length < equalityTolerance/neonMath.Metre {
+		return neonMath.ZeroVec2D, length
+	}
+	return separation.Scale(1.0 / length), length
+}
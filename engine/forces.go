@@ -0,0 +1,74 @@
+package engine
+
+import (
+	neonMath "Neon/engine/math"
+	"Neon/entities"
+)
+
+// ForceGenerator is anything that applies forces to a set of bodies, every generator is invoked at the start of each timestep
+// generators should only accumulate forces (through ApplyForce and friends), the manager handles integrating them
+type ForceGenerator interface {
+	UpdateForces(bodies []entities.Body, dt float64)
+}
+
+// ForceGeneratorFunc allows an ordinary function to be used as a force generator, this is the easiest way to add a custom field
+type ForceGeneratorFunc func(bodies []entities.Body, dt float64)
+
+// UpdateForces just calls the function
+func (generator ForceGeneratorFunc) UpdateForces(bodies []entities.Body, dt float64) {
+	generator(bodies, dt)
+}
+
+// forceRegistration ties a generator to the bodies it acts on, global generators act on every tracked body
+type forceRegistration struct {
+	generator ForceGenerator
+	bodies    []entities.Body
+	global    bool
+}
+
+// LinearDrag opposes the motion of a body, the drag is Coefficient * |v| + QuadraticCoefficient * |v|^2
+type LinearDrag struct {
+	Coefficient          float64
+	QuadraticCoefficient float64
+}
+
+// UpdateForces applies drag to every body
+func (drag LinearDrag) UpdateForces(bodies []entities.Body, dt float64) {
+	for _, body := range bodies {
+		state := body.GetState()
+		speed := state.Velocity.Length()
+		if speed == 0 {
+			continue
+		}
+
+		magnitude := drag.Coefficient*speed + drag.QuadraticCoefficient*speed*speed
+		state.ApplyForce(state.Velocity.Scale(-magnitude / speed))
+	}
+}
+
+// AngularDrag opposes the rotation of a body, the torque is just -Coefficient * w
+type AngularDrag struct {
+	Coefficient float64
+}
+
+// UpdateForces applies angular drag to every body
+func (drag AngularDrag) UpdateForces(bodies []entities.Body, dt float64) {
+	for _, body := range bodies {
+		state := body.GetState()
+		state.ApplyTorque(-drag.Coefficient * state.AngularVelocity)
+	}
+}
+
+// ConstantField is a uniform field, Acceleration acts on every body regardless of its mass (eg. gravity) while Force is applied as is (eg. wind)
+type ConstantField struct {
+	Acceleration neonMath.Vector2D
+	Force        neonMath.Vector2D
+}
+
+// UpdateForces applies the field to every body
+func (field ConstantField) UpdateForces(bodies []entities.Body, dt float64) {
+	for _, body := range bodies {
+		state := body.GetState()
+		state.ApplyForce(field.Acceleration.Scale(state.Mass).Add(field.Force))
+	}
+}
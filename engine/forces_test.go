@@ -0,0 +1,100 @@
+package engine
+
+import (
+	neonMath "Neon/engine/math"
+	"Neon/entities"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestForceGenerators(t *testing.T) {
+	falling := entities.NewCircle(neonMath.Vector2D{X: 0, Y: 0}, 10)
+	falling.State.Mass, falling.State.RotationalInertia = 2.0, 1.0
+
+	spinning := entities.NewCircle(neonMath.Vector2D{X: 1000, Y: 0}, 10)
+	spinning.State.Mass, spinning.State.RotationalInertia = 1.0, 1.0
+	spinning.State.AngularVelocity = 2.0
+
+	manager := NewPhysicsManager()
+	manager.BeginTracking(&falling, &spinning)
+	manager.SetGravity(neonMath.Vector2D{Y: -9.8})
+	manager.AddForceGenerator(AngularDrag{Coefficient: 0.5}, &spinning)
+
+	manager.applyForces(0.1)
+	if math.Abs(falling.State.Velocity.Y+0.98) > 1e-9 {
+		t.Errorf("expected gravity to accelerate the circle to -0.98 m/s, got %v", falling.State.Velocity)
+	}
+	if math.Abs(spinning.State.AngularVelocity-1.9) > 1e-9 {
+		t.Errorf("expected angular drag to slow the circle to 1.9 rad/s, got %f", spinning.State.AngularVelocity)
+	}
+	if falling.State.Force != neonMath.ZeroVec2D || spinning.State.Torque != 0 {
+		t.Errorf("expected the accumulators to be cleared after integrating")
+	}
+}
+
+func TestDragAndFields(t *testing.T) {
+	slow := entities.NewCircle(neonMath.Vector2D{X: 0, Y: 0}, 10)
+	fast := entities.NewCircle(neonMath.Vector2D{X: 1000, Y: 0}, 10)
+	slow.State.Velocity, fast.State.Velocity = neonMath.Vector2D{X: 1, Y: 1}, neonMath.Vector2D{X: 2, Y: 2}
+
+	// linear drag points straight against the velocity and doubles when the velocity does
+	LinearDrag{Coefficient: 0.5}.UpdateForces([]entities.Body{&slow, &fast}, 0.1)
+	if slow.State.Force.Sub(slow.State.Velocity.Scale(-0.5)).Length() > 1e-9 {
+		t.Errorf("expected linear drag of %v, got %v", slow.State.Velocity.Scale(-0.5), slow.State.Force)
+	}
+	if fast.State.Force.Sub(slow.State.Force.Scale(2)).Length() > 1e-9 {
+		t.Errorf("expected twice the velocity to give twice the drag, got %v and %v", slow.State.Force, fast.State.Force)
+	}
+
+	// a body at rest feels no drag at all
+	resting := entities.NewCircle(neonMath.Vector2D{X: 2000, Y: 0}, 10)
+	LinearDrag{Coefficient: 0.5, QuadraticCoefficient: 0.5}.UpdateForces([]entities.Body{&resting}, 0.1)
+	if resting.State.Force != neonMath.ZeroVec2D {
+		t.Errorf("expected no drag on a body at rest, got %v", resting.State.Force)
+	}
+
+	// the force of a field is the same no matter how heavy the body is
+	light, heavy := entities.NewCircle(neonMath.Vector2D{X: 0, Y: 0}, 10), entities.NewCircle(neonMath.Vector2D{X: 1000, Y: 0}, 10)
+	light.State.Mass, heavy.State.Mass = 1.0, 10.0
+	wind := neonMath.Vector2D{X: 3, Y: -1}
+	ConstantField{Force: wind}.UpdateForces([]entities.Body{&light, &heavy}, 0.1)
+	if light.State.Force != wind || heavy.State.Force != wind {
+		t.Errorf("expected both bodies to feel a force of %v, got %v and %v", wind, light.State.Force, heavy.State.Force)
+	}
+}
+
+func TestNBodyGravity(t *testing.T) {
+	// two 1kg masses a metre apart
+	a := entities.NewCircle(neonMath.Vector2D{X: 0, Y: 0}, 10)
+	b := entities.NewCircle(neonMath.Vector2D{X: neonMath.Metre, Y: 0}, 10)
+	a.State.Mass, b.State.Mass = 1.0, 1.0
+
+	NBodyGravity{G: 2.0}.UpdateForces([]entities.Body{&a, &b}, 0.1)
+	if a.State.Force.Sub(neonMath.Vector2D{X: 2.0}).Length() > 1e-9 || b.State.Force.Sub(neonMath.Vector2D{X: -2.0}).Length() > 1e-9 {
+		t.Errorf("expected forces of (2, 0) and (-2, 0), got %v and %v", a.State.Force, b.State.Force)
+	}
+
+	// the Barnes-Hut approximation should be close to the exact result
+	random := rand.New(rand.NewSource(1))
+	exact, approximate := randomCircles(300, random), []*entities.Circle{}
+	exactBodies, approximateBodies := []entities.Body{}, []entities.Body{}
+	for _, circle := range exact {
+		circle.State.Mass = 1 + random.Float64()
+		clone := *circle
+		approximate = append(approximate, &clone)
+		exactBodies, approximateBodies = append(exactBodies, circle), append(approximateBodies, &clone)
+	}
+
+	NBodyGravity{G: 1.0, Softening: 0.1}.UpdateForces(exactBodies, 0.1)
+	NBodyGravity{G: 1.0, Softening: 0.1, Theta: 0.5}.UpdateForces(approximateBodies, 0.1)
+	// individual bodies near the middle feel almost no net force so we compare the error against the average force instead
+	totalError, totalForce := 0.0, 0.0
+	for i := range exact {
+		totalError += exact[i].State.Force.Sub(approximate[i].State.Force).Length()
+		totalForce += exact[i].State.Force.Length()
+	}
+	if totalError/totalForce > 0.02 {
+		t.Errorf("Barnes-Hut forces are off by %.1f%% on average", totalError/totalForce*100)
+	}
+}
@@ -0,0 +1,162 @@
+package engine
+
+import (
+	"Neon/entities"
+	"math"
+)
+
+/*
+	Islands are groups of dynamic bodies that are touching (or jointed to) each other, either directly or through other bodies in the group. Static and kinematic
+	bodies never join islands since they dont transmit anything between the bodies resting on them. An island can only ever go to sleep as a whole,
+	otherwise a sleeping body at the bottom of a stack would stop supporting the bodies still moving on top of it
+*/
+
+// SleepConfig configures when islands of bodies are put to sleep
+type SleepConfig struct {
+	AllowSleeping bool
+
+	LinearThreshold  float64 // bodies moving slower than this (in m/s) are considered to be resting
+	AngularThreshold float64 // bodies rotating slower than this (in rad/s) are considered to be resting
+	TimeToSleep      float64 // how long (in seconds) every body in an island has to be resting before the island falls asleep
+}
+
+// DefaultSleepConfig is used unless told otherwise
+var DefaultSleepConfig = SleepConfig{
+	AllowSleeping: true,
+
+	LinearThreshold:  0.01,
+	AngularThreshold: 2.0 * math.Pi / 180.0,
+	TimeToSleep:      0.5,
+}
+
+// island is just the set of bodies within it
+type island []entities.Body
+
+// islandBuilder groups bodies into islands with a disjoint set
+type islandBuilder struct {
+	parent map[entities.Body]entities.Body
+}
+
+func newIslandBuilder(bodies []entities.Body) islandBuilder {
+	builder := islandBuilder{parent: make(map[entities.Body]entities.Body)}
+	for _, body := range bodies {
+		if isAwakeDynamic(body) {
+			builder.parent[body] = body
+		}
+	}
+	return builder
+}
+
+// find returns the representative of the island that contains the body
+func (builder islandBuilder) find(body entities.Body) entities.Body {
+	for builder.parent[body] != body {
+		builder.parent[body] = builder.parent[builder.parent[body]]
+		body = builder.parent[body]
+	}
+	return body
+}
+
+// connect merges the islands of two bodies that are touching, bodies that arent in the builder (static, kinematic or sleeping bodies) are ignored
+func (builder islandBuilder) connect(bodyA, bodyB entities.Body) {
+	_, hasA := builder.parent[bodyA]
+	_, hasB := builder.parent[bodyB]
+	if hasA && hasB {
+		builder.parent[builder.find(bodyA)] = builder.find(bodyB)
+	}
+}
+
+// islands returns every island that was built
+func (builder islandBuilder) islands() []island {
+	indices := make(map[entities.Body]int)
+	islands := []island{}
+
+	for body := range builder.parent {
+		root := builder.find(body)
+		if _, exists := indices[root]; !exists {
+			indices[root] = len(islands)
+			islands = append(islands, island{})
+		}
+		islands[indices[root]] = append(islands[indices[root]], body)
+	}
+	return islands
+}
+
+// isAwakeDynamic determines if a body is a dynamic body that is currently awake
+func isAwakeDynamic(body entities.Body) bool {
+	state := body.GetState()
+	return state.Type == entities.DynamicBody && !state.Sleeping
+}
+
+// isActive determines if a body could disturb the bodies around it, this is any awake dynamic body or any kinematic body that is moving
+func isActive(body entities.Body) bool {
+	state := body.GetState()
+	switch state.Type {
+	case entities.DynamicBody:
+		return !state.Sleeping
+	case entities.KinematicBody:
+		return state.Velocity.Length() != 0 || state.AngularVelocity != 0
+	}
+	return false
+}
+
+// updateSleep advances the rest timers of every awake body and puts any island that has been resting long enough to sleep
+func (receiver *PhysicsManager) updateSleep(manifolds []ContactManifold, dt float64) {
+	if !receiver.sleepConfig.AllowSleeping {
+		return
+	}
+
+	builder := newIslandBuilder(receiver.trackingEntities)
+	for _, manifold := range manifolds {
+		if !manifold.isSensor() {
+			builder.connect(manifold.IncidentFrame, manifold.ReferenceFrame)
+		}
+	}
+	for _, joint := range receiver.joints {
+		builder.connect(joint.Bodies())
+	}
+
+	for _, island := range builder.islands() {
+		minSleepTime := math.Inf(1)
+		for _, body := range island {
+			state := body.GetState()
+			if state.Velocity.Length() > receiver.sleepConfig.LinearThreshold || math.Abs(state.AngularVelocity) > receiver.sleepConfig.AngularThreshold {
+				state.SleepTime = 0
+			} else {
+				state.SleepTime += dt
+			}
+			minSleepTime = math.Min(minSleepTime, state.SleepTime)
+		}
+
+		if minSleepTime >= receiver.sleepConfig.TimeToSleep {
+			for _, body := range island {
+				body.GetState().Sleep()
+				receiver.sleepingIslands[body] = island
+			}
+		}
+	}
+}
+
+// wakeIslands wakes every sleeping island that has had one of its bodies woken up (by an impulse or by the user)
+func (receiver *PhysicsManager) wakeIslands() {
+	for body, island := range receiver.sleepingIslands {
+		if !body.GetState().Sleeping {
+			receiver.wakeIsland(island)
+		}
+	}
+}
+
+// wakeIsland wakes every body within an island
+func (receiver *PhysicsManager) wakeIsland(island island) {
+	for _, body := range island {
+		body.GetState().Wake()
+		delete(receiver.sleepingIslands, body)
+	}
+}
+
+// WakeUp wakes a body up along with every other body it was sleeping with
+func (receiver *PhysicsManager) WakeUp(body entities.Body) {
+	body.GetState().Wake()
+	if island, exists := receiver.sleepingIslands[body]; exists {
+		receiver.wakeIsland(island)
+	}
+}
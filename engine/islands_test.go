@@ -0,0 +1,95 @@
+package engine
+
+import (
+	neonMath "Neon/engine/math"
+	"Neon/entities"
+	"testing"
+)
+
+func TestSleeping(t *testing.T) {
+	ground := groundFixture()
+	ground.State.Material = entities.Material{StaticFriction: 0.6, DynamicFriction: 0.4}
+
+	manager := NewPhysicsManager()
+	manager.SetGravity(neonMath.Vector2D{Y: -9.8})
+	manager.BeginTracking(ground)
+
+	// a stack of two boxes and a third box off on its own
+	boxes := []*entities.Polygon{}
+	for _, origin := range []neonMath.Vector2D{{X: 0, Y: 0}, {X: 0, Y: 60}, {X: 300, Y: 0}} {
+		box := entities.NewPolygon([]neonMath.Vector2D{
+			origin.Add(neonMath.Vector2D{X: -30, Y: 60}), origin.Add(neonMath.Vector2D{X: 30, Y: 60}),
+			origin.Add(neonMath.Vector2D{X: 30, Y: 0}), origin.Add(neonMath.Vector2D{X: -30, Y: 0}),
+		})
+		box.State.Material = entities.Material{StaticFriction: 0.6, DynamicFriction: 0.4, Density: 1.0}
+		entities.ComputeMass(&box)
+
+		boxes = append(boxes, &box)
+		manager.BeginTracking(&box)
+	}
+
+	for i := 0; i < 180; i++ {
+		manager.NextTimeStep(1.0 / 60.0)
+	}
+	for i, box := range boxes {
+		if !box.State.Sleeping {
+			t.Fatalf("box %v should have fallen asleep, its moving at %v", i, box.State.Velocity)
+		}
+	}
+
+	// hitting the bottom of the stack should wake the whole stack but not the box on its own
+	boxes[0].State.ApplyImpulse(neonMath.Vector2D{X: 0.1}, neonMath.ZeroVec2D)
+	manager.NextTimeStep(1.0 / 60.0)
+	if boxes[0].State.Sleeping || boxes[1].State.Sleeping || !boxes[2].State.Sleeping {
+		t.Errorf("only the stack should have woken up, the boxes are sleeping: %v %v %v", boxes[0].State.Sleeping, boxes[1].State.Sleeping, boxes[2].State.Sleeping)
+	}
+
+	manager.WakeUp(boxes[2])
+	if boxes[2].State.Sleeping {
+		t.Errorf("the box on its own should have been woken up")
+	}
+}
+
+func TestRemovingSupportWakes(t *testing.T) {
+	ground := groundFixture()
+	ground.State.Material = entities.Material{StaticFriction: 0.6, DynamicFriction: 0.4}
+
+	manager := NewPhysicsManager()
+	manager.SetGravity(neonMath.Vector2D{Y: -9.8})
+	manager.BeginTracking(ground)
+
+	boxes := []*entities.Polygon{}
+	for _, origin := range []neonMath.Vector2D{{X: 0, Y: 0}, {X: 0, Y: 60}} {
+		box := entities.NewPolygon([]neonMath.Vector2D{
+			origin.Add(neonMath.Vector2D{X: -30, Y: 60}), origin.Add(neonMath.Vector2D{X: 30, Y: 60}),
+			origin.Add(neonMath.Vector2D{X: 30, Y: 0}), origin.Add(neonMath.Vector2D{X: -30, Y: 0}),
+		})
+		box.State.Material = entities.Material{StaticFriction: 0.6, DynamicFriction: 0.4, Density: 1.0}
+		entities.ComputeMass(&box)
+
+		boxes = append(boxes, &box)
+		manager.BeginTracking(&box)
+	}
+
+	for i := 0; i < 180; i++ {
+		manager.NextTimeStep(1.0 / 60.0)
+	}
+	if !boxes[0].State.Sleeping || !boxes[1].State.Sleeping {
+		t.Fatalf("the stack should have fallen asleep on the ground")
+	}
+
+	// pulling the ground out from under the stack should wake it and let it fall
+	rested := boxes[0].State.CentroidPosition.Y
+	manager.StopTracking(ground)
+	for i := 0; i < 30; i++ {
+		manager.NextTimeStep(1.0 / 60.0)
+	}
+	for i, box := range boxes {
+		if box.State.Sleeping {
+			t.Errorf("box %v should have woken up once the ground was removed", i)
+		}
+	}
+	if boxes[0].State.CentroidPosition.Y >= rested {
+		t.Errorf("the stack should fall once the ground is removed, it stayed at %v", boxes[0].State.CentroidPosition.Y)
+	}
+}
@@ -0,0 +1,252 @@
+package engine
+
+import (
+	neonMath "Neon/engine/math"
+	"Neon/entities"
+	"math"
+)
+
+/*
+	Joints constrain how two bodies can move relative to each other, they are solved as velocity constraints in the same iterations as the contacts
+	so that the impulses from both settle down together. Just like the contacts the impulses are accumulated (and warm started across timesteps)
+	Solving the velocities still lets the joints drift apart a little every timestep, so once everything has moved the drift is removed by
+	directly shifting the bodies back into place (this is the non linear Gauss-Seidel position solve from Box2D)
+*/
+
+// Joint is a constraint between two bodies
+type Joint interface {
+	// Bodies returns the two bodies connected by the joint
+	Bodies() (entities.Body, entities.Body)
+	// collideConnected determines if the two bodies connected by the joint can still collide with each other
+	collideConnected() bool
+
+	// prepare works out everything the joint needs for the timestep (and warm starts it), this is called once before the velocity iterations
+	prepare(config SolverConfig, dt float64)
+	// solveVelocities applies whatever impulse is needed to satisfy the joint, this is called once per velocity iteration
+	solveVelocities()
+	// solvePositions moves the bodies back into place after they have been integrated, this is called once per position iteration
+	solvePositions()
+}
+
+// JointBodies is the part shared by every joint, the two bodies being connected and where the joint is attached to each of them
+type JointBodies struct {
+	BodyA, BodyB entities.Body
+
+	// Anchors are relative to the centroid of each body (in world units) when the body isnt rotated, they rotate along with the body
+	LocalAnchorA, LocalAnchorB neonMath.Vector2D
+
+	CollideConnected bool // by default bodies connected by a joint dont collide with each other
+}
+
+// Bodies returns the two bodies connected by the joint
+func (joint *JointBodies) Bodies() (entities.Body, entities.Body) {
+	return joint.BodyA, joint.BodyB
+}
+
+func (joint *JointBodies) collideConnected() bool {
+	return joint.CollideConnected
+}
+
+// WorldAnchors returns where the joint is currently attached to each body, in world coordinates
+func (joint *JointBodies) WorldAnchors() (neonMath.Vector2D, neonMath.Vector2D) {
+	return worldAnchor(joint.BodyA, joint.LocalAnchorA), worldAnchor(joint.BodyB, joint.LocalAnchorB)
+}
+
+// solverBodies gathers up the data the solver needs about both bodies
+func (joint *JointBodies) solverBodies() (jointBody, jointBody) {
+	return newJointBody(joint.BodyA, joint.LocalAnchorA), newJointBody(joint.BodyB, joint.LocalAnchorB)
+}
+
+// localAnchor converts a point in world coordinates into an anchor on the body
+func localAnchor(body entities.Body, point neonMath.Vector2D) neonMath.Vector2D {
+	state := body.GetState()
+	return point.Sub(state.CentroidPosition).Rotate(-state.Angle)
+}
+
+// worldAnchor converts an anchor on the body into world coordinates
+func worldAnchor(body entities.Body, anchor neonMath.Vector2D) neonMath.Vector2D {
+	state := body.GetState()
+	return state.CentroidPosition.Add(anchor.Rotate(state.Angle))
+}
+
+// jointBody is everything the solver needs to know about one of the bodies of a joint, all vectors here are in metres
+type jointBody struct {
+	body                entities.Body
+	state               *entities.EntityState
+	invMass, invInertia float64
+	r                   neonMath.Vector2D // offset of the anchor from the centroid
+}
+
+func newJointBody(body entities.Body, anchor neonMath.Vector2D) jointBody {
+	state := body.GetState()
+	invMass, invInertia := inversePhysicalData(state)
+
+	return jointBody{
+		body:       body,
+		state:      state,
+		invMass:    invMass,
+		invInertia: invInertia,
+		r:          anchor.Rotate(state.Angle).Scale(1.0 / neonMath.Metre),
+	}
+}
+
+// anchor returns the position of the anchor in metres
+func (body jointBody) anchor() neonMath.Vector2D {
+	return body.state.CentroidPosition.Scale(1.0 / neonMath.Metre).Add(body.r)
+}
+
+// velocity returns the velocity of the anchor
+func (body jointBody) velocity() neonMath.Vector2D {
+	return body.state.Velocity.Add(body.r.CrossUpwardsWithVec(body.state.AngularVelocity))
+}
+
+// inverseMass computes the inverse of the mass "felt" by an impulse along the axis at the anchor
+func (body jointBody) inverseMass(axis neonMath.Vector2D) float64 {
+	rn := body.r.CrossMag(axis)
+	return body.invMass + body.invInertia*rn*rn
+}
+
+// applyImpulse applies an impulse at the anchor
+func (body jointBody) applyImpulse(impulse neonMath.Vector2D) {
+	body.state.Velocity = body.state.Velocity.Add(impulse.Scale(body.invMass))
+	body.state.AngularVelocity += body.invInertia * body.r.CrossMag(impulse)
+}
+
+// applyPositionImpulse moves the body as if the impulse was applied at the anchor for a second
+func (body jointBody) applyPositionImpulse(impulse neonMath.Vector2D) {
+	body.state.ShiftCentroid(impulse.Scale(body.invMass * neonMath.Metre))
+	body.body.Rotate(body.invInertia * body.r.CrossMag(impulse))
+}
+
+// applyAngularImpulse applies an angular impulse about the centroid
+func (body jointBody) applyAngularImpulse(impulse float64) {
+	body.state.AngularVelocity += body.invInertia * impulse
+}
+
+// applyAngularPositionImpulse rotates the body as if the angular impulse was applied for a second
+func (body jointBody) applyAngularPositionImpulse(impulse float64) {
+	body.body.Rotate(body.invInertia * impulse)
+}
+
+// applyJointImpulse applies an impulse to body b and the opposite impulse to body a
+func applyJointImpulse(a, b jointBody, impulse neonMath.Vector2D) {
+	a.applyImpulse(impulse.Scale(-1))
+	b.applyImpulse(impulse)
+}
+
+// applyJointPositionImpulse moves body b along the impulse and body a against it
+func applyJointPositionImpulse(a, b jointBody, impulse neonMath.Vector2D) {
+	a.applyPositionImpulse(impulse.Scale(-1))
+	b.applyPositionImpulse(impulse)
+}
+
+// pointMass computes the inverse of the mass matrix "felt" by an impulse (in any direction) at the anchors, this is what keeps two anchors together
+func pointMass(a, b jointBody) neonMath.Matrix2 {
+	invMass := a.invMass + b.invMass
+	return neonMath.Matrix2{M: [2][2]float64{
+		{invMass + a.invInertia*a.r.Y*a.r.Y + b.invInertia*b.r.Y*b.r.Y, -a.invInertia*a.r.X*a.r.Y - b.invInertia*b.r.X*b.r.Y},
+		{-a.invInertia*a.r.X*a.r.Y - b.invInertia*b.r.X*b.r.Y, invMass + a.invInertia*a.r.X*a.r.X + b.invInertia*b.r.X*b.r.X},
+	}}
+}
+
+// solvePointPosition moves two bodies so that their anchors line up again
+func solvePointPosition(a, b jointBody) {
+	separation := b.anchor().Sub(a.anchor())
+	if length := separation.Length(); length > maxJointCorrection {
+		separation = separation.Scale(maxJointCorrection / length)
+	}
+
+	applyJointPositionImpulse(a, b, solveMatrix(pointMass(a, b), separation.Scale(-1)))
+}
+
+// solveMatrix solves Ax = b for x, if A cant be inverted then there is nothing to solve and x is just 0
+func solveMatrix(A neonMath.Matrix2, b neonMath.Vector2D) neonMath.Vector2D {
+	if A.Determinant() == 0 {
+		return neonMath.ZeroVec2D
+	}
+	return A.Inverse().VectorMultiply(b)
+}
+
+// clamp restricts x to [lower, upper]
+func clamp(x, lower, upper float64) float64 {
+	return math.Max(lower, math.Min(x, upper))
+}
+
+// softConstraint turns a spring (stiffness in N/m and damping in Ns/m) into the softness (gamma) and bias of a velocity constraint
+// see Erin Catto's "Soft Constraints" talk, the spring is solved implicitly so it stays stable no matter how stiff it is
+func softConstraint(stiffness, damping, error, dt float64) (float64, float64) {
+	gamma := dt * (damping + dt*stiffness)
+	if gamma != 0 {
+		gamma = 1.0 / gamma
+	}
+	return gamma, error * dt * stiffness * gamma
+}
+
+// inverse is 1/x, except that nothing (or infinity) has no inverse
+func inverse(x float64) float64 {
+	if x == 0 || math.IsInf(x, 0) {
+		return 0
+	}
+	return 1.0 / x
+}
+
+// AddJoint adds a set of joints to the manager, both bodies of every joint should be tracked by the manager as well
+func (receiver *PhysicsManager) AddJoint(joints ...Joint) {
+	for _, joint := range joints {
+		bodyA, bodyB := joint.Bodies()
+		receiver.WakeUp(bodyA)
+		receiver.WakeUp(bodyB)
+	}
+	receiver.joints = append(receiver.joints, joints...)
+}
+
+// RemoveJoint removes a set of joints from the manager, the bodies they connected are woken up since they might now be free to move
+func (receiver *PhysicsManager) RemoveJoint(joints ...Joint) {
+	for _, joint := range joints {
+		for i, tracked := range receiver.joints {
+			if tracked == joint {
+				receiver.joints = append(receiver.joints[:i], receiver.joints[i+1:]...)
+
+				bodyA, bodyB := joint.Bodies()
+				receiver.WakeUp(bodyA)
+				receiver.WakeUp(bodyB)
+				break
+			}
+		}
+	}
+}
+
+// removeJoints removes every joint attached to the body
+func (receiver *PhysicsManager) removeJoints(body entities.Body) {
+	for _, joint := range append([]Joint{}, receiver.joints...) {
+		if bodyA, bodyB := joint.Bodies(); bodyA == body || bodyB == body {
+			receiver.RemoveJoint(joint)
+		}
+	}
+}
+
+// activeJoints returns every joint that has to be solved this timestep, this is any joint with an awake dynamic body
+// a sleeping body attached to an awake one is woken up, otherwise the awake body would be pulling on something that cant move
+func (receiver *PhysicsManager) activeJoints() []Joint {
+	active := []Joint{}
+	for _, joint := range receiver.joints {
+		bodyA, bodyB := joint.Bodies()
+		if isAwakeDynamic(bodyA) || isAwakeDynamic(bodyB) {
+			receiver.WakeUp(bodyA)
+			receiver.WakeUp(bodyB)
+			active = append(active, joint)
+		}
+	}
+	return active
+}
+
+// jointConnected determines if two bodies are connected by a joint that doesnt let them collide
+func (receiver PhysicsManager) jointConnected(bodyA, bodyB entities.Body) bool {
+	for _, joint := range receiver.joints {
+		a, b := joint.Bodies()
+		if ((a == bodyA && b == bodyB) || (a == bodyB && b == bodyA)) && !joint.collideConnected() {
+			return true
+		}
+	}
+	return false
+}
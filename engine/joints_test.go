@@ -0,0 +1,226 @@
+package engine
+
+import (
+	neonMath "Neon/engine/math"
+	"Neon/entities"
+	"math"
+	"testing"
+)
+
+// jointScene sets up a static pin at the origin and a box (with the given half width) centred at centre
+func jointScene(centre neonMath.Vector2D, halfWidth float64) (PhysicsManager, *entities.Polygon, *entities.Polygon) {
+	pin := entities.NewPolygon([]neonMath.Vector2D{{X: -5, Y: 5}, {X: 5, Y: 5}, {X: 5, Y: -5}, {X: -5, Y: -5}})
+	pin.State.Type = entities.StaticBody
+
+	box := entities.NewPolygon([]neonMath.Vector2D{
+		centre.Add(neonMath.Vector2D{X: -halfWidth, Y: halfWidth}), centre.Add(neonMath.Vector2D{X: halfWidth, Y: halfWidth}),
+		centre.Add(neonMath.Vector2D{X: halfWidth, Y: -halfWidth}), centre.Add(neonMath.Vector2D{X: -halfWidth, Y: -halfWidth}),
+	})
+	box.State.Material.Density = 1.0
+	entities.ComputeMass(&box)
+
+	manager := NewPhysicsManager()
+	manager.SetGravity(neonMath.Vector2D{Y: -9.8})
+	manager.BeginTracking(&pin, &box)
+
+	return manager, &pin, &box
+}
+
+// anchorDistance is how far apart the world anchors of a joint currently are
+func anchorDistance(joint *JointBodies) float64 {
+	anchorA, anchorB := joint.WorldAnchors()
+	return anchorB.Sub(anchorA).Length()
+}
+
+func TestDistanceJoint(t *testing.T) {
+	// the box hangs off its top edge, so the anchor has to rotate with the box as it swings
+	manager, pin, box := jointScene(neonMath.Vector2D{X: 200, Y: -50}, 50)
+	joint := NewDistanceJoint(pin, box, neonMath.ZeroVec2D, neonMath.Vector2D{Y: 50})
+	manager.AddJoint(joint)
+
+	for i := 0; i < 120; i++ {
+		manager.NextTimeStep(1.0 / 60.0)
+		if distance := anchorDistance(&joint.JointBodies); math.Abs(distance-200) > 0.5 {
+			t.Fatalf("the anchors should stay 200 units apart, they are %v apart after %v steps", distance, i)
+		}
+	}
+	if box.State.Angle == 0 {
+		t.Errorf("the box should have started rotating as it swung")
+	}
+
+	// springs stretch until they hold up the box, after that they should settle down at mg/k
+	manager, pin, box = jointScene(neonMath.Vector2D{X: 0, Y: -200}, 50)
+	spring := NewDistanceJoint(pin, box, neonMath.ZeroVec2D, neonMath.ZeroVec2D)
+	spring.Stiffness, spring.Damping = 10, 2
+	manager.AddJoint(spring)
+
+	for i := 0; i < 600; i++ {
+		manager.NextTimeStep(1.0 / 60.0)
+	}
+	stretch := box.State.Mass * 9.8 / spring.Stiffness * neonMath.Metre
+	if distance := anchorDistance(&spring.JointBodies); math.Abs(distance-200-stretch) > 2 {
+		t.Errorf("the spring should have stretched by %v, its %v long", stretch, distance)
+	}
+}
+
+func TestRopeJoint(t *testing.T) {
+	manager, pin, box := jointScene(neonMath.Vector2D{X: 0, Y: -100}, 20)
+	rope := NewRopeJoint(pin, box, neonMath.ZeroVec2D, neonMath.ZeroVec2D, 200)
+	manager.AddJoint(rope)
+
+	// the rope is slack to start with so the box just falls
+	for i := 0; i < 5; i++ {
+		manager.NextTimeStep(1.0 / 60.0)
+	}
+	if rope.impulse != 0 {
+		t.Errorf("a slack rope shouldnt pull on anything, it applied an impulse of %v", rope.impulse)
+	}
+
+	for i := 0; i < 120; i++ {
+		manager.NextTimeStep(1.0 / 60.0)
+	}
+	if distance := anchorDistance(&rope.JointBodies); math.Abs(distance-200) > 2 {
+		t.Errorf("the box should be hanging off the end of the rope, its %v away", distance)
+	}
+
+	// bodies connected by a joint dont collide unless they are told to
+	if manager.shouldCollide(pin, box) {
+		t.Errorf("bodies connected by a joint shouldnt collide")
+	}
+	rope.CollideConnected = true
+	if !manager.shouldCollide(pin, box) {
+		t.Errorf("bodies connected by a joint should collide if CollideConnected is set")
+	}
+}
+
+func TestRevoluteJoint(t *testing.T) {
+	// a pendulum pinned at its left edge, it should swing down without the pin coming apart
+	manager, pin, box := jointScene(neonMath.Vector2D{X: 50, Y: 0}, 50)
+	hinge := NewRevoluteJoint(pin, box, neonMath.ZeroVec2D)
+	manager.AddJoint(hinge)
+
+	for i := 0; i < 120; i++ {
+		manager.NextTimeStep(1.0 / 60.0)
+		if distance := anchorDistance(&hinge.JointBodies); distance > 0.5 {
+			t.Fatalf("the anchors should stay pinned together, they are %v apart after %v steps", distance, i)
+		}
+	}
+	if hinge.Angle() > -math.Pi/4 {
+		t.Errorf("the pendulum should have swung down, its at an angle of %v", hinge.Angle())
+	}
+
+	// limits stop the pendulum from swinging down too far
+	manager, pin, box = jointScene(neonMath.Vector2D{X: 50, Y: 0}, 50)
+	hinge = NewRevoluteJoint(pin, box, neonMath.ZeroVec2D)
+	hinge.EnableLimit, hinge.LowerAngle, hinge.UpperAngle = true, -math.Pi/6, math.Pi/6
+	manager.AddJoint(hinge)
+
+	for i := 0; i < 120; i++ {
+		manager.NextTimeStep(1.0 / 60.0)
+		if hinge.Angle() < hinge.LowerAngle-2*angularSlop {
+			t.Fatalf("the pendulum should stop at its lower limit, its at an angle of %v after %v steps", hinge.Angle(), i)
+		}
+	}
+	if math.Abs(hinge.Angle()-hinge.LowerAngle) > 2*angularSlop {
+		t.Errorf("the pendulum should be resting on its lower limit, its at an angle of %v", hinge.Angle())
+	}
+
+	// a motor pinned at the centroid of the box just has to spin it up to speed
+	manager, pin, box = jointScene(neonMath.Vector2D{X: 0, Y: 0}, 50)
+	hinge = NewRevoluteJoint(pin, box, box.State.CentroidPosition)
+	hinge.EnableMotor, hinge.MotorSpeed, hinge.MaxMotorTorque = true, 2.0, 100
+	manager.AddJoint(hinge)
+
+	for i := 0; i < 60; i++ {
+		manager.NextTimeStep(1.0 / 60.0)
+	}
+	if math.Abs(hinge.Speed()-hinge.MotorSpeed) > 1e-3 {
+		t.Errorf("the motor should spin the box at %v rad/s, its spinning at %v", hinge.MotorSpeed, hinge.Speed())
+	}
+}
+
+func TestPrismaticJoint(t *testing.T) {
+	// the box is only allowed to slide horizontally, so gravity shouldnt be able to do anything to it
+	manager, pin, box := jointScene(neonMath.Vector2D{X: 100, Y: 0}, 20)
+	slider := NewPrismaticJoint(pin, box, box.State.CentroidPosition, neonMath.Vector2D{X: 1})
+	manager.AddJoint(slider)
+
+	for i := 0; i < 60; i++ {
+		manager.NextTimeStep(1.0 / 60.0)
+	}
+	if math.Abs(box.State.CentroidPosition.Y) > 0.5 || math.Abs(box.State.Angle) > angularSlop || math.Abs(slider.Translation()) > 0.5 {
+		t.Errorf("the box should be held in place, it ended up at %v with an angle of %v", box.State.CentroidPosition, box.State.Angle)
+	}
+
+	// the motor slides the box along until it hits the upper limit
+	slider.EnableMotor, slider.MotorSpeed, slider.MaxMotorForce = true, 1.0, 100
+	slider.EnableLimit, slider.LowerTranslation, slider.UpperTranslation = true, 0, 100
+	for i := 0; i < 30; i++ {
+		manager.NextTimeStep(1.0 / 60.0)
+	}
+	if math.Abs(box.State.Velocity.X-slider.MotorSpeed) > 1e-3 {
+		t.Errorf("the motor should slide the box at %v m/s, its moving at %v", slider.MotorSpeed, box.State.Velocity)
+	}
+
+	for i := 0; i < 120; i++ {
+		manager.NextTimeStep(1.0 / 60.0)
+	}
+	if translation := slider.Translation(); math.Abs(translation-slider.UpperTranslation) > linearSlop*neonMath.Metre {
+		t.Errorf("the box should have stopped at the upper limit, its slid %v units", translation)
+	}
+}
+
+func TestWheelJoint(t *testing.T) {
+	manager, pin, _ := jointScene(neonMath.Vector2D{X: 1000, Y: 1000}, 20)
+	wheel := entities.NewCircle(neonMath.Vector2D{X: 0, Y: -100}, 30)
+	wheel.State.Material.Density = 1.0
+	entities.ComputeMass(&wheel)
+	manager.BeginTracking(&wheel)
+
+	suspension := NewWheelJoint(pin, &wheel, wheel.State.CentroidPosition, neonMath.Vector2D{Y: 1})
+	suspension.Stiffness, suspension.Damping = 10, 2
+	suspension.EnableMotor, suspension.MotorSpeed, suspension.MaxMotorTorque = true, 5, 10
+	manager.AddJoint(suspension)
+
+	for i := 0; i < 600; i++ {
+		manager.NextTimeStep(1.0 / 60.0)
+	}
+
+	// the wheel should sag until the spring holds it up, without wandering off the axis
+	sag := -wheel.State.Mass * 9.8 / suspension.Stiffness * neonMath.Metre
+	if math.Abs(suspension.Translation()-sag) > 2 || math.Abs(wheel.State.CentroidPosition.X) > 0.5 {
+		t.Errorf("the wheel should have sagged by %v along the axis, it ended up at %v", sag, wheel.State.CentroidPosition)
+	}
+	if math.Abs(wheel.State.AngularVelocity-suspension.MotorSpeed) > 1e-3 {
+		t.Errorf("the motor should spin the wheel at %v rad/s, its spinning at %v", suspension.MotorSpeed, wheel.State.AngularVelocity)
+	}
+}
+
+func TestWeldJoint(t *testing.T) {
+	// a box welded to the side of the pin should just stick out without drooping
+	manager, pin, box := jointScene(neonMath.Vector2D{X: 55, Y: 0}, 50)
+	weld := NewWeldJoint(pin, box, neonMath.Vector2D{X: 5})
+	manager.AddJoint(weld)
+
+	for i := 0; i < 120; i++ {
+		manager.NextTimeStep(1.0 / 60.0)
+	}
+	if math.Abs(box.State.Angle) > angularSlop || box.State.CentroidPosition.Sub(neonMath.Vector2D{X: 55}).Length() > 1 {
+		t.Errorf("the box should be held in place by the weld, it ended up at %v with an angle of %v", box.State.CentroidPosition, box.State.Angle)
+	}
+
+	// a soft weld bends until the spring can hold the box up, mg * lever arm = stiffness * angle
+	manager, pin, box = jointScene(neonMath.Vector2D{X: 55, Y: 0}, 50)
+	weld = NewWeldJoint(pin, box, neonMath.Vector2D{X: 5})
+	weld.AngularStiffness, weld.AngularDamping = 20, 2
+	manager.AddJoint(weld)
+
+	for i := 0; i < 600; i++ {
+		manager.NextTimeStep(1.0 / 60.0)
+	}
+	anchorA, anchorB := weld.WorldAnchors()
+	leverArm := box.State.CentroidPosition.Sub(anchorB).X / neonMath.Metre
+	if expected := -box.State.Mass * 9.8 * leverArm / weld.AngularStiffness; math.Abs(box.State.Angle-expected) > 0.01 || anchorB.Sub(anchorA).Length() > 0.5 {
+		t.Errorf("the soft weld should have bent to an angle of %v, its at %v", expected, box.State.Angle)
+	}
+}
@@ -1,59 +1,259 @@
 package engine
 
 import (
+	neonMath "Neon/engine/math"
 	"Neon/entities"
 )
 
 // Physics manager keeps a list to entities it is currently tracking, it is additionally responsible for detecting collisions between only these objects
 // The manager furthermore should resolve these collisions
 type PhysicsManager struct {
-	trackingEntities   []*entities.Polygon
+	trackingEntities   []entities.Body
+	broadphase         Broadphase
 	collisionCallbacks []func(manifold ContactManifold)
+	contactFilter      func(bodyA, bodyB entities.Body) bool // optional, this can veto a pair of bodies before they are collided
+	mixingRules        MixingRules
+	solverConfig       SolverConfig
+	impulseCache       impulseCache // impulses from the last timestep, used for warm starting
+	joints             []Joint
+
+	// Islands of bodies that have come to rest
+	sleepConfig     SleepConfig
+	sleepingIslands map[entities.Body]island
+
+	// Bodies currently overlapping a sensor
+	sensorOverlaps  map[BodyPair]bool
+	sensorCallbacks []sensorCallbacks
+
+	// Bodies currently touching each other
+	contactPairs     map[BodyPair]bool
+	contactListeners []ContactListener
+
+	// Forces acting on the tracked entities, gravity is in m/s^2
+	gravity         neonMath.Vector2D
+	forceGenerators []forceRegistration
 }
 
+// NewPhysicsManager creates a manager that uses a dynamic AABB tree as its broadphase
 func NewPhysicsManager() PhysicsManager {
 	return PhysicsManager{
-		trackingEntities: []*entities.Polygon{},
+		trackingEntities: []entities.Body{},
+		broadphase:       NewAABBTree(aabbMargin),
+		mixingRules:      DefaultMixingRules,
+		solverConfig:     DefaultSolverConfig,
+		sleepConfig:      DefaultSleepConfig,
+		sleepingIslands:  make(map[entities.Body]island),
+		sensorOverlaps:   make(map[BodyPair]bool),
+		contactPairs:     make(map[BodyPair]bool),
+	}
+}
+
+// Adds a set of bodies to the tracking list, these can be any mesh the engine knows how to collide
+func (receiver *PhysicsManager) BeginTracking(bodies ...entities.Body) {
+	receiver.trackingEntities = append(receiver.trackingEntities, bodies...)
+	for _, body := range bodies {
+		receiver.broadphase.Insert(body)
+	}
+}
+
+// StopTracking removes a set of bodies from the tracking list
+func (receiver *PhysicsManager) StopTracking(bodies ...entities.Body) {
+	for _, body := range bodies {
+		for i, tracked := range receiver.trackingEntities {
+			if tracked == body {
+				receiver.trackingEntities = append(receiver.trackingEntities[:i], receiver.trackingEntities[i+1:]...)
+
+				// whatever was resting on the body has to wake up and fall, static and kinematic bodies never join an island so the broadphase is asked what was touching it
+				for _, touching := range receiver.broadphase.QueryAABB(body.GetBoundingBox()) {
+					receiver.WakeUp(touching)
+				}
+				receiver.broadphase.Remove(body)
+
+				// any sensors it was in also have to be told it left and any joints attached to it go with it
+				receiver.WakeUp(body)
+				receiver.removeSensorOverlaps(body)
+				receiver.removeContactPairs(body)
+				receiver.removeJoints(body)
+				break
+			}
+		}
 	}
 }
 
-// Adds a polygon to the tracking list
-func (receiver *PhysicsManager) BeginTracking(polys ...*entities.Polygon) {
-	receiver.trackingEntities = append(receiver.trackingEntities, polys...)
+// SetBroadphase swaps out the broadphase used to cull collision pairs, every tracked body is moved over to the new broadphase
+func (receiver *PhysicsManager) SetBroadphase(broadphase Broadphase) {
+	for _, body := range receiver.trackingEntities {
+		broadphase.Insert(body)
+	}
+	receiver.broadphase = broadphase
 }
 
 // Adds a callback function to the set of collision callback functions if a collision ever does occur
+// The callbacks are invoked on every timestep that the bodies are touching, use a ContactListener to tell new contacts apart from ongoing ones
 func (receiver *PhysicsManager) AddCallback(callbacks ...func(manifold ContactManifold)) {
 	receiver.collisionCallbacks = append(receiver.collisionCallbacks, callbacks...)
 }
 
-// ResolveCollisions identifies if any collisions are present and resolves them if they are
-func (receiver PhysicsManager) ResolveCollisions() {
-	for i, a := range receiver.trackingEntities {
-		for _, b := range receiver.trackingEntities[i+1:] {
-			if collides, manifold := DetermineCollision(a, b); collides {
-				manifold.ResolveCollision()
-
-				// Perform the callback operations
-				for _, callback := range receiver.collisionCallbacks {
-					callback(manifold)
+// SetContactFilter sets a predicate that is consulted before any pair of bodies is collided, if it returns false then the pair is ignored
+// The predicate is only consulted for pairs that the collision filters of the bodies already allow, passing nil removes it
+func (receiver *PhysicsManager) SetContactFilter(filter func(bodyA, bodyB entities.Body) bool) {
+	receiver.contactFilter = filter
+}
+
+// SetMixingRules sets how the materials of two colliding bodies are combined
+func (receiver *PhysicsManager) SetMixingRules(rules MixingRules) {
+	receiver.mixingRules = rules
+}
+
+// SetSolverConfig configures the contact solver used by NextTimeStep
+func (receiver *PhysicsManager) SetSolverConfig(config SolverConfig) {
+	receiver.solverConfig = config
+}
+
+// SetSleepConfig configures when resting bodies are put to sleep, if sleeping is disabled then everything is woken up
+func (receiver *PhysicsManager) SetSleepConfig(config SleepConfig) {
+	receiver.sleepConfig = config
+	if !config.AllowSleeping {
+		for _, island := range receiver.sleepingIslands {
+			receiver.wakeIsland(island)
+		}
+	}
+}
+
+// SetGravity sets the acceleration due to gravity (in m/s^2) that acts on every tracked entity
+func (receiver *PhysicsManager) SetGravity(gravity neonMath.Vector2D) {
+	receiver.gravity = gravity
+}
+
+// AddForceGenerator registers a force generator, if no bodies are provided then the generator acts on every tracked body
+func (receiver *PhysicsManager) AddForceGenerator(generator ForceGenerator, bodies ...entities.Body) {
+	receiver.forceGenerators = append(receiver.forceGenerators, forceRegistration{
+		generator: generator,
+		bodies:    bodies,
+		global:    len(bodies) == 0,
+	})
+}
+
+// applyForces runs every force generator (and gravity) and then integrates the accumulated forces into the velocities of the entities
+func (receiver *PhysicsManager) applyForces(dt float64) {
+	if receiver.gravity != neonMath.ZeroVec2D {
+		ConstantField{Acceleration: receiver.gravity}.UpdateForces(receiver.trackingEntities, dt)
+	}
+
+	for _, registration := range receiver.forceGenerators {
+		bodies := registration.bodies
+		if registration.global {
+			bodies = receiver.trackingEntities
+		}
+		registration.generator.UpdateForces(bodies, dt)
+	}
+
+	for _, e := range receiver.trackingEntities {
+		e.GetState().IntegrateForces(dt)
+	}
+}
+
+// DetectCollisions determines every collision between the tracked entities and returns their manifolds, the collision callbacks are invoked for every collision
+func (receiver PhysicsManager) DetectCollisions() []ContactManifold {
+	manifolds := []ContactManifold{}
+
+	// Only the pairs that survive the broadphase are worth running SAT on
+	receiver.broadphase.Update()
+	for _, pair := range receiver.broadphase.ComputePairs() {
+		if !receiver.shouldCollide(pair.A, pair.B) {
+			continue
+		}
+
+		// compound bodies can touch with several of their shapes at once, each of those gets its own manifold
+		for _, manifold := range ComputeContactManifolds(pair.A, pair.B) {
+			manifolds = append(manifolds, manifold)
+
+			// anything that gets hit wakes up, sensors dont actually hit anything though
+			for _, body := range []entities.Body{pair.A, pair.B} {
+				if body.GetState().Sleeping && !manifold.isSensor() {
+					receiver.WakeUp(body)
 				}
 			}
+
+			// Perform the callback operations
+			for _, callback := range receiver.collisionCallbacks {
+				callback(manifold)
+			}
 		}
 	}
+
+	receiver.updateSensorOverlaps(manifolds)
+	receiver.updateContactPairs(manifolds)
+	return manifolds
+}
+
+// shouldCollide determines if a pair of bodies is worth colliding, static and kinematic bodies are never moved by collisions so they never collide with each other
+// bodies that are sleeping (or not moving at all) also arent worth colliding, nothing would happen. Finally the pair has to get past the filters
+// Sensors are the exception, they keep track of bodies that have fallen asleep within them (but sensors never detect other sensors)
+func (receiver PhysicsManager) shouldCollide(bodyA, bodyB entities.Body) bool {
+	stateA, stateB := bodyA.GetState(), bodyB.GetState()
+
+	hasDynamic := stateA.Type == entities.DynamicBody || stateB.Type == entities.DynamicBody
+	if !hasDynamic || (stateA.Sensor && stateB.Sensor) {
+		return false
+	}
+	if !(stateA.Sensor || stateB.Sensor) && !(isActive(bodyA) || isActive(bodyB)) {
+		return false
+	}
+	if !stateA.Filter.ShouldCollide(stateB.Filter) || receiver.jointConnected(bodyA, bodyB) {
+		return false
+	}
+	return receiver.contactFilter == nil || receiver.contactFilter(bodyA, bodyB)
 }
 
-// NextTimeStep just progresses everything to the next timestep, just numerical integration.... Note: Every entitiy already has methods for progressing its state
+// ResolveCollisions identifies if any collisions are present and resolves them if they are, the overlapping entities are then just pushed apart
+// Note: NextTimeStep doesnt use this, it solves the collisions alongside the integration of the entities
+func (receiver PhysicsManager) ResolveCollisions() {
+	manifolds := receiver.DetectCollisions()
+	solver := newContactSolver(manifolds, receiver.solverConfig, receiver.mixingRules, 0, receiver.preSolve)
+	solver.solveVelocities()
+	solver.postSolve(receiver.postSolve)
+
+	// only the contacts that were actually solved are separated, this skips sensors and anything the pre-solve hooks disabled
+	for _, constraint := range solver.constraints {
+		constraint.manifold.separate()
+	}
+}
+
+// NextTimeStep progresses everything to the next timestep, every contact is gathered up front and solved iteratively before the entities are integrated
+// Note: Every entitiy already has methods for progressing its state
 func (receiver *PhysicsManager) NextTimeStep(dt float64) {
-	progressEntities := func() {
-		for _, e := range receiver.trackingEntities {
+	// Anything that was woken up since the last step wakes up the rest of its island
+	receiver.wakeIslands()
+
+	// Apply the forces first so that the new velocities are used for the rest of the step
+	receiver.applyForces(dt)
+
+	// Solve the velocity constraints for every contact and joint together, starting from the impulses of the last timestep
+	manifolds := receiver.DetectCollisions()
+	solver := newContactSolver(manifolds, receiver.solverConfig, receiver.mixingRules, dt, receiver.preSolve)
+	if receiver.solverConfig.WarmStarting {
+		solver.warmStart(receiver.impulseCache)
+	}
+	solver.addJoints(receiver.activeJoints())
+	solver.solveVelocities()
+	solver.postSolve(receiver.postSolve)
+	receiver.impulseCache = solver.impulses()
+
+	// Bullets are swept along their path so that they stop at whatever they hit
+	for _, e := range receiver.trackingEntities {
+		state := e.GetState()
+		switch {
+		case state.Sleeping:
+			continue
+		case state.Bullet && state.Type == entities.DynamicBody:
+			receiver.advanceBullet(e, dt)
+		default:
 			e.NextTimeStep(dt)
 		}
 	}
 
-	// Progress entities, resolve entities and progress again :D
-	// The second progression is for smoother results
-	progressEntities()
-	receiver.ResolveCollisions()
-	progressEntities()
+	// Finally push apart anything that is still overlapping and put anything that has come to rest to sleep
+	solver.correctPositions()
+	receiver.updateSleep(manifolds, dt)
 }
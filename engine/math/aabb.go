@@ -0,0 +1,75 @@
+package neonMath
+
+import "math"
+
+// AABB is an axis aligned bounding box, it is defined by its bottom left (Min) and top right (Max) corners
+type AABB struct {
+	Min, Max Vector2D
+}
+
+// Overlaps determines if two boxes overlap, boxes that are just touching are considered overlapping
+func (box AABB) Overlaps(other AABB) bool {
+	return box.Min.X <= other.Max.X && other.Min.X <= box.Max.X &&
+		box.Min.Y <= other.Max.Y && other.Min.Y <= box.Max.Y
+}
+
+// Contains determines if other lies completely within box
+func (box AABB) Contains(other AABB) bool {
+	return box.Min.X <= other.Min.X && box.Min.Y <= other.Min.Y &&
+		other.Max.X <= box.Max.X && other.Max.Y <= box.Max.Y
+}
+
+// Union computes the smallest box that contains both boxes
+func (box AABB) Union(other AABB) AABB {
+	return AABB{
+		Min: Vector2D{X: math.Min(box.Min.X, other.Min.X), Y: math.Min(box.Min.Y, other.Min.Y)},
+		Max: Vector2D{X: math.Max(box.Max.X, other.Max.X), Y: math.Max(box.Max.Y, other.Max.Y)},
+	}
+}
+
+// Fatten grows the box by margin in every direction
+func (box AABB) Fatten(margin float64) AABB {
+	return AABB{
+		Min: box.Min.Sub(Vector2D{X: margin, Y: margin}),
+		Max: box.Max.Add(Vector2D{X: margin, Y: margin}),
+	}
+}
+
+// Translate moves the box by offset
+func (box AABB) Translate(offset Vector2D) AABB {
+	return AABB{Min: box.Min.Add(offset), Max: box.Max.Add(offset)}
+}
+
+// Perimeter of the box, this is the "cost" of a box when building bounding volume hierarchies
+func (box AABB) Perimeter() float64 {
+	return 2.0 * ((box.Max.X - box.Min.X) + (box.Max.Y - box.Min.Y))
+}
+
+// IntersectsRay determines if a ray starting at origin and travelling maxDistance along direction passes through the box
+// This is just the slab test, the ray is clipped against the pair of lines bounding the box along each axis
+func (box AABB) IntersectsRay(origin, direction Vector2D, maxDistance float64) bool {
+	lower, upper := 0.0, maxDistance
+
+	for _, axis := range [][4]float64{
+		{origin.X, direction.X, box.Min.X, box.Max.X},
+		{origin.Y, direction.Y, box.Min.Y, box.Max.Y},
+	} {
+		start, delta, min, max := axis[0], axis[1], axis[2], axis[3]
+		if delta == 0 {
+			if start < min || start > max {
+				return false
+			}
+			continue
+		}
+
+		near, far := (min-start)/delta, (max-start)/delta
+		if near > far {
+			near, far = far, near
+		}
+		lower, upper = math.Max(lower, near), math.Min(upper, far)
+		if lower > upper {
+			return false
+		}
+	}
+	return true
+}
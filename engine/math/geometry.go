@@ -29,6 +29,26 @@ func LineIntervalIntersection(interval, line [2]Vector2D) Vector2D {
 	return intersection
 }
 
+// ComputeAreaCentroid computes the centroid of the area enclosed by a polygon (rather than just the average of its vertices)
+// The vertices have to be in order, degenerate polygons with no area just fall back to the average of the vertices
+func ComputeAreaCentroid(vertices []Vector2D) Vector2D {
+	area, centroid, average := 0.0, ZeroVec2D, ZeroVec2D
+
+	for i, a := range vertices {
+		b := vertices[(i+1)%len(vertices)]
+		cross := a.CrossMag(b)
+
+		area += 0.5 * cross
+		centroid = centroid.Add(a.Add(b).Scale(cross))
+		average = average.Add(a)
+	}
+
+	if math.Abs(area) <= 1e-9 {
+		return average.Scale(1.0 / float64(len(vertices)))
+	}
+	return centroid.Scale(1.0 / (6 * area))
+}
+
 // Given 3 points: A, B and C; ComputeOutwardsNormal computes the normal vector of (A - B) that points away from C
 func ComputeOutwardsNormal(A, B, C Vector2D) Vector2D {
 	normalVectorAttempt := A.Sub(B).Normal().Normalise()
@@ -83,3 +103,50 @@ func ProjectPointOntoLine(point Vector2D, line [2]Vector2D) Vector2D {
 	projection := point.Project(line[1].Sub(line[0]))
 	return line[0].Add(projection)
 }
+
+// ClosestPointOnInterval returns the point on the interval [a, b] that is closest to point
+func ClosestPointOnInterval(point Vector2D, interval [2]Vector2D) Vector2D {
+	direction := interval[1].Sub(interval[0])
+	if direction.Dot(direction) == 0 {
+		return interval[0]
+	}
+
+	mu := math.Max(0, math.Min(1, point.Sub(interval[0]).ScalarProject(direction)))
+	return interval[0].Add(direction.Scale(mu))
+}
+
+// ClosestPointsBetweenIntervals returns the pair of points (one on each interval) that are closest to each other
+// the method is just the standard one of minimising the distance between two parametrised lines and then clamping the parameters
+func ClosestPointsBetweenIntervals(intervalA, intervalB [2]Vector2D) (Vector2D, Vector2D) {
+	dA, dB := intervalA[1].Sub(intervalA[0]), intervalB[1].Sub(intervalB[0])
+	r := intervalA[0].Sub(intervalB[0])
+	a, e, f := dA.Dot(dA), dB.Dot(dB), dB.Dot(r)
+	clamp := func(x float64) float64 { return math.Max(0, math.Min(1, x)) }
+
+	// degenerate intervals are just points
+	if a == 0 && e == 0 {
+		return intervalA[0], intervalB[0]
+	}
+	if a == 0 {
+		return intervalA[0], intervalB[0].Add(dB.Scale(clamp(f / e)))
+	}
+
+	c := dA.Dot(r)
+	if e == 0 {
+		return intervalA[0].Add(dA.Scale(clamp(-c / a))), intervalB[0]
+	}
+
+	b := dA.Dot(dB)
+	s, t := 0.0, 0.0
+	if denominator := a*e - b*b; denominator != 0 {
+		s = clamp((b*f - c*e) / denominator)
+	}
+
+	t = (b*s + f) / e
+	if t < 0 {
+		t, s = 0, clamp(-c/a)
+	} else if t > 1 {
+		t, s = 1, clamp((b-c)/a)
+	}
+	return intervalA[0].Add(dA.Scale(s)), intervalB[0].Add(dB.Scale(t))
+}
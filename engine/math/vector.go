@@ -131,6 +131,14 @@ func (v Vector2D) CrossUpwardsWithVec(upwards float64) Vector2D {
 	}
 }
 
+// Rotate rotates the vector about the origin by theta
+func (v Vector2D) Rotate(theta float64) Vector2D {
+	return Vector2D{
+		X: v.X*math.Cos(theta) - v.Y*math.Sin(theta),
+		Y: v.X*math.Sin(theta) + v.Y*math.Cos(theta),
+	}
+}
+
 // Projection functions project vector v onto vector k
 func (v Vector2D) Project(k Vector2D) Vector2D {
 	return k.Scale((v.Dot(k)) /
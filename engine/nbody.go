@@ -0,0 +1,175 @@
+package engine
+
+import (
+	neonMath "Neon/engine/math"
+	"Neon/entities"
+	"math"
+)
+
+// barnesHutMaxDepth caps the depth of the Barnes-Hut tree, bodies that are (almost) on top of each other just get lumped into the same leaf
+const barnesHutMaxDepth = 32
+
+// NBodyGravity is a force generator that makes every body attract every other body via Newton's law of gravitation
+// Forces are softened (F = G * m1 * m2 * r / (r^2 + e^2)^(3/2)) so that bodies passing really close to each other dont get flung off to infinity
+// Note: distances are all measured in metres
+type NBodyGravity struct {
+	G         float64
+	Softening float64
+
+	// Theta is the opening angle for the Barnes-Hut approximation, a region of space of width s at a distance d is treated as a single
+	// point mass whenever s / d < Theta. If Theta is 0 then every pair is computed exactly (which is O(n^2))
+	Theta float64
+}
+
+// UpdateForces applies the gravitational attraction between all the bodies
+func (gravity NBodyGravity) UpdateForces(bodies []entities.Body, dt float64) {
+	if gravity.Theta <= 0 {
+		gravity.exactForces(bodies)
+		return
+	}
+
+	tree := newBarnesHutTree(bodies)
+	for _, body := range bodies {
+		state := body.GetState()
+		if state.Mass == 0 {
+			continue
+		}
+		state.ApplyForce(gravity.barnesHutForce(tree, state))
+	}
+}
+
+// attraction computes the force that a point mass at position exerts on the state
+func (gravity NBodyGravity) attraction(state *entities.EntityState, position neonMath.Vector2D, mass float64) neonMath.Vector2D {
+	separation := position.Sub(state.CentroidPosition).Scale(1.0 / neonMath.Metre)
+	softenedDistance := separation.Dot(separation) + gravity.Softening*gravity.Softening
+	if softenedDistance == 0 {
+		return neonMath.ZeroVec2D
+	}
+
+	return separation.Scale(gravity.G * state.Mass * mass / math.Pow(softenedDistance, 1.5))
+}
+
+// exactForces just considers every pair of bodies, by Newton's third law each pair only has to be computed once
+func (gravity NBodyGravity) exactForces(bodies []entities.Body) {
+	for i, a := range bodies {
+		stateA := a.GetState()
+		for _, b := range bodies[i+1:] {
+			stateB := b.GetState()
+
+			force := gravity.attraction(stateA, stateB.CentroidPosition, stateB.Mass)
+			stateA.ApplyForce(force)
+			stateB.ApplyForce(force.Scale(-1.0))
+		}
+	}
+}
+
+// barnesHutForce walks the tree accumulating the force on the state, regions that are far enough away are approximated by their centre of mass
+func (gravity NBodyGravity) barnesHutForce(node *barnesHutNode, state *entities.EntityState) neonMath.Vector2D {
+	if node == nil || node.mass == 0 {
+		return neonMath.ZeroVec2D
+	}
+
+	if node.isLeaf() {
+		force := neonMath.ZeroVec2D
+		for _, other := range node.states {
+			if other != state {
+				force = force.Add(gravity.attraction(state, other.CentroidPosition, other.Mass))
+			}
+		}
+		return force
+	}
+
+	// the ratio is computed in world units as both the width and the distance are in the same units
+	distance := node.centreOfMass.Sub(state.CentroidPosition).Length()
+	if distance > 0 && 2.0*node.halfWidth/distance < gravity.Theta {
+		return gravity.attraction(state, node.centreOfMass, node.mass)
+	}
+
+	force := neonMath.ZeroVec2D
+	for _, child := range node.children {
+		force = force.Add(gravity.barnesHutForce(child, state))
+	}
+	return force
+}
+
+// barnesHutNode is a square region of space within the Barnes-Hut tree, it tracks the total mass and centre of mass of everything within it
+type barnesHutNode struct {
+	centre    neonMath.Vector2D
+	halfWidth float64
+	depth     int
+
+	mass         float64
+	centreOfMass neonMath.Vector2D
+
+	states   []*entities.EntityState // only defined for leaves
+	children []*barnesHutNode        // either nil or the 4 quadrants of the node, quadrants may be nil if they are empty
+}
+
+func (node *barnesHutNode) isLeaf() bool {
+	return node.children == nil
+}
+
+// newBarnesHutTree builds a tree over every body that has mass
+func newBarnesHutTree(bodies []entities.Body) *barnesHutNode {
+	bounds := neonMath.AABB{Min: neonMath.BigVec2D, Max: neonMath.BigVec2D.Scale(-1.0)}
+	for _, body := range bodies {
+		position := body.GetState().CentroidPosition
+		bounds = bounds.Union(neonMath.AABB{Min: position, Max: position})
+	}
+
+	root := &barnesHutNode{
+		centre:    bounds.Min.Add(bounds.Max).Scale(0.5),
+		halfWidth: math.Max(bounds.Max.X-bounds.Min.X, bounds.Max.Y-bounds.Min.Y)/2.0 + 1.0,
+	}
+	for _, body := range bodies {
+		if state := body.GetState(); state.Mass != 0 {
+			root.insert(state)
+		}
+	}
+	return root
+}
+
+// insert pushes a state down into the leaf that contains it, updating the centre of mass of every node along the way
+func (node *barnesHutNode) insert(state *entities.EntityState) {
+	totalMass := node.mass + state.Mass
+	node.centreOfMass = node.centreOfMass.Scale(node.mass).Add(state.CentroidPosition.Scale(state.Mass)).Scale(1.0 / totalMass)
+	node.mass = totalMass
+
+	if node.isLeaf() {
+		node.states = append(node.states, state)
+		if len(node.states) == 1 || node.depth >= barnesHutMaxDepth {
+			return
+		}
+
+		// the leaf now has two bodies in it so it needs to be split up
+		node.children = make([]*barnesHutNode, 4)
+		states := node.states
+		node.states = nil
+		for _, s := range states {
+			node.childFor(s.CentroidPosition).insert(s)
+		}
+		return
+	}
+
+	node.childFor(state.CentroidPosition).insert(state)
+}
+
+// childFor returns the quadrant of the node containing position, creating it if it doesnt exist yet
+func (node *barnesHutNode) childFor(position neonMath.Vector2D) *barnesHutNode {
+	quadrant, offset := 0, neonMath.Vector2D{X: -0.5, Y: -0.5}
+	if position.X >= node.centre.X {
+		quadrant, offset.X = quadrant+1, 0.5
+	}
+	if position.Y >= node.centre.Y {
+		quadrant, offset.Y = quadrant+2, 0.5
+	}
+
+	if node.children[quadrant] == nil {
+		node.children[quadrant] = &barnesHutNode{
+			centre:    node.centre.Add(offset.Scale(node.halfWidth)),
+			halfWidth: node.halfWidth / 2.0,
+			depth:     node.depth + 1,
+		}
+	}
+	return node.children[quadrant]
+}
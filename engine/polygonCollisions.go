@@ -8,8 +8,11 @@ import (
 
 // This is completely responsible for determining if two objects collide and computing a collision manifold for them
 type ContactManifold struct {
-	IncidentFrame  *entities.Polygon
-	ReferenceFrame *entities.Polygon
+	IncidentFrame  entities.Body
+	ReferenceFrame entities.Body
+	// The shapes that were actually hit, these are just the frames unless a frame is a compound body
+	IncidentShape  entities.Body
+	ReferenceShape entities.Body
 
 	IncidentFace  []int
 	ReferenceFace []int
@@ -18,10 +21,46 @@ type ContactManifold struct {
 	ContactCount    int
 	CollisionPoints []neonMath.Vector2D
 	ContactDepths   []float64
+	ContactIDs      []ContactID // the features that generated each collision point, this may be empty for meshes without vertices
 }
 
-// ComputeContactManifold computes a contact manifold for two polygon meshes
-func ComputeContactManifold(poly_a, poly_b *entities.Polygon) ContactManifold {
+// ContactID identifies a contact point by the features that generated it, the same contact between the same frames has the same ID across timesteps
+// A contact is either a vertex of the incident face or the point where the incident face was clipped by the side of the reference face at ReferenceVertex
+type ContactID struct {
+	ReferenceFace, IncidentFace     [2]int
+	ReferenceVertex, IncidentVertex int
+}
+
+// newContactID creates a contact ID from the faces of a manifold, unused features are -1
+func newContactID(referenceFace, incidentFace []int, referenceVertex, incidentVertex int) ContactID {
+	faceKey := func(face []int) [2]int {
+		if len(face) != 2 {
+			return [2]int{-1, -1}
+		}
+		if face[0] > face[1] {
+			return [2]int{face[1], face[0]}
+		}
+		return [2]int{face[0], face[1]}
+	}
+
+	return ContactID{
+		ReferenceFace:   faceKey(referenceFace),
+		IncidentFace:    faceKey(incidentFace),
+		ReferenceVertex: referenceVertex,
+		IncidentVertex:  incidentVertex,
+	}
+}
+
+// contactID returns the ID of the i-th collision point, meshes that dont provide IDs just have their contacts numbered
+func (manifold ContactManifold) contactID(i int) ContactID {
+	if i < len(manifold.ContactIDs) {
+		return manifold.ContactIDs[i]
+	}
+	return newContactID(manifold.ReferenceFace, manifold.IncidentFace, -1, i)
+}
+
+// ComputePolygonContactManifold computes a contact manifold for two polygon meshes
+func ComputePolygonContactManifold(poly_a, poly_b *entities.Polygon) ContactManifold {
 	mtv := entities.SAT(*poly_a, *poly_b) // note that the MTV always points from A to B
 
 	// If there is no collision then the MTV is the zero vector which we need to account for
@@ -35,16 +74,18 @@ func ComputeContactManifold(poly_a, poly_b *entities.Polygon) ContactManifold {
 	edgeCandidateA, perpA := poly_a.DetermineSupportingEdge(collisionNormal)
 	edgeCandidateB, perpB := poly_b.DetermineSupportingEdge(collisionNormal.Scale(-1.0))
 
+	// A is preferred as the reference polygon unless B is noticeably better, otherwise resting contacts between identical faces would keep swapping roles
+	// and the contact IDs would change every timestep
 	referencePolygon, referenceFace := poly_a, edgeCandidateA
 	incidentPolygon, incidentFace := poly_b, edgeCandidateB
-	if perpB >= perpA {
+	if perpB > perpA+referenceFaceTolerance {
 		referencePolygon, referenceFace, incidentPolygon, incidentFace = poly_b, edgeCandidateB, poly_a, edgeCandidateA
 		mtv = mtv.Scale(-1.0)
 	}
 
 	// Now we need to actually perform the clipping of our reference_polygon onto our incident_polygon
 	// After that is done, we simply delete all points of the clipped polygon that are not "behind" the reference face, this is all implemented in the polygon_clip method
-	contactPoints, pointDepths := polygonClip(*incidentPolygon, *referencePolygon, incidentFace, referenceFace)
+	contactPoints, pointDepths, contactIDs := polygonClip(*incidentPolygon, *referencePolygon, incidentFace, referenceFace)
 
 	return ContactManifold{
 		IncidentFrame:  incidentPolygon,
@@ -57,6 +98,7 @@ func ComputeContactManifold(poly_a, poly_b *entities.Polygon) ContactManifold {
 		ContactCount:    len(contactPoints),
 		CollisionPoints: contactPoints,
 		ContactDepths:   pointDepths,
+		ContactIDs:      contactIDs,
 	}
 }
 
@@ -77,11 +119,12 @@ func determineRequiredClippingEdges(referencePoly entities.Polygon, referenceFac
 	return clippingSet
 }
 
-// Performs the clipping required for manifold computation
-func polygonClip(incidentPoly, referencePoly entities.Polygon, incidentFace, referenceFace []int) ([]neonMath.Vector2D, []float64) {
+// Performs the clipping required for manifold computation, alongside the contact points it returns the ID of every point
+func polygonClip(incidentPoly, referencePoly entities.Polygon, incidentFace, referenceFace []int) ([]neonMath.Vector2D, []float64, []ContactID) {
 
 	// get the actual "values" for the incident and reference face
-	incidentFaceEdge := incidentPoly.GetEdgeCoordinates(incidentFace)
+	incidentVertices := incidentPoly.GetEdgeCoordinates(incidentFace)
+	incidentFaceEdge := incidentVertices
 	referenceFaceEdge := referencePoly.GetEdgeCoordinates(referenceFace)
 
 	// for simplicity we can compute a "set" of edges that the incident polygon needs to be clipped against
@@ -95,13 +138,39 @@ func polygonClip(incidentPoly, referencePoly entities.Polygon, incidentFace, ref
 	}
 
 	// finally the "manifold" is simply points that have actually penetrated the reference_poly, hence they lie below the reference face
-	return neonMath.LiesBehindLine(
+	contactPoints, pointDepths := neonMath.LiesBehindLine(
 		incidentFaceEdge[:],
 		referenceFaceEdge,
 		neonMath.ComputeOutwardsNormal(referenceFaceEdge[0], referenceFaceEdge[1], referencePoly.State.CentroidPosition))
+
+	// every point that survived is either an untouched vertex of the incident face or it was created by clipping against one of the sides
+	contactIDs := make([]ContactID, len(contactPoints))
+	for i, point := range contactPoints {
+		referenceVertex, incidentVertex := -1, -1
+		for j, vertex := range incidentVertices {
+			if point == vertex {
+				incidentVertex = incidentFace[j]
+			}
+		}
+
+		if incidentVertex == -1 {
+			closestDistance := math.Inf(1)
+			for _, clippingEdge := range requiredClipping {
+				line := referencePoly.GetEdgeCoordinates(clippingEdge)
+				if distance := point.Sub(neonMath.ProjectPointOntoLine(point, line)).Length(); distance < closestDistance {
+					closestDistance, referenceVertex = distance, clippingEdge[0]
+				}
+			}
+		}
+
+		contactIDs[i] = newContactID(referenceFace, incidentFace, referenceVertex, incidentVertex)
+	}
+
+	return contactPoints, pointDepths, contactIDs
 }
 
-func DetermineCollision(polyA *entities.Polygon, polyB *entities.Polygon) (bool, ContactManifold) {
-	contactManifold := ComputeContactManifold(polyA, polyB)
+// DeterminePolygonCollision determines if two polygons collide
+func DeterminePolygonCollision(polyA *entities.Polygon, polyB *entities.Polygon) (bool, ContactManifold) {
+	contactManifold := ComputePolygonContactManifold(polyA, polyB)
 	return contactManifold.ContactCount != 0, contactManifold
 }
@@ -0,0 +1,313 @@
+package engine
+
+import (
+	neonMath "Neon/engine/math"
+	"Neon/entities"
+	"math"
+)
+
+// PrismaticJoint only lets two bodies slide relative to each other along an axis (like a piston), they cant rotate relative to each other at all
+// the translation along the axis can be limited and driven by a motor
+type PrismaticJoint struct {
+	JointBodies
+
+	LocalAxisA     neonMath.Vector2D // the axis the bodies slide along, relative to body a
+	ReferenceAngle float64           // the angle of body b relative to body a (in radians)
+
+	// Limits on the translation of the joint (in world units)
+	EnableLimit                        bool
+	LowerTranslation, UpperTranslation float64
+
+	// The motor tries to slide the joint at MotorSpeed (in m/s) using at most MaxMotorForce (in N)
+	EnableMotor   bool
+	MotorSpeed    float64
+	MaxMotorForce float64
+
+	a, b                jointBody
+	axis, perpendicular axialConstraint
+	axialMass           float64
+	mass                neonMath.Matrix2 // couples the perpendicular and angular constraints
+	translation, dt     float64
+
+	impulse                    neonMath.Vector2D // perpendicular and angular impulses
+	motorImpulse               float64
+	lowerImpulse, upperImpulse float64
+}
+
+// NewPrismaticJoint creates a prismatic joint at the anchor (in world coordinates) that slides along the axis (also in world coordinates)
+func NewPrismaticJoint(bodyA, bodyB entities.Body, anchor, axis neonMath.Vector2D) *PrismaticJoint {
+	return &PrismaticJoint{
+		JointBodies:    JointBodies{BodyA: bodyA, BodyB: bodyB, LocalAnchorA: localAnchor(bodyA, anchor), LocalAnchorB: localAnchor(bodyB, anchor)},
+		LocalAxisA:     axis.Normalise().Rotate(-bodyA.GetState().Angle),
+		ReferenceAngle: bodyB.GetState().Angle - bodyA.GetState().Angle,
+	}
+}
+
+// Translation returns how far (in world units) the joint has slid along its axis
+func (joint *PrismaticJoint) Translation() float64 {
+	return jointTranslation(&joint.JointBodies, joint.LocalAxisA)
+}
+
+func (joint *PrismaticJoint) prepare(config SolverConfig, dt float64) {
+	joint.a, joint.b = joint.solverBodies()
+	joint.axis, joint.perpendicular = newAxialConstraints(joint.a, joint.b, joint.LocalAxisA)
+	joint.axialMass = inverse(joint.axis.inverseMass(joint.a, joint.b))
+	joint.mass = joint.perpendicular.angularMass(joint.a, joint.b)
+	joint.translation, joint.dt = joint.axis.translation(joint.a, joint.b), dt
+
+	if !joint.EnableMotor || !config.WarmStarting {
+		joint.motorImpulse = 0
+	}
+	if !joint.EnableLimit || !config.WarmStarting {
+		joint.lowerImpulse, joint.upperImpulse = 0, 0
+	}
+	if !config.WarmStarting {
+		joint.impulse = neonMath.ZeroVec2D
+	}
+
+	joint.axis.applyImpulse(joint.a, joint.b, joint.motorImpulse+joint.lowerImpulse-joint.upperImpulse)
+	joint.perpendicular.applyImpulse(joint.a, joint.b, joint.impulse.X)
+	joint.a.applyAngularImpulse(-joint.impulse.Y)
+	joint.b.applyAngularImpulse(joint.impulse.Y)
+}
+
+func (joint *PrismaticJoint) solveVelocities() {
+	a, b := joint.a, joint.b
+
+	if joint.EnableMotor {
+		impulse := joint.axialMass * (joint.MotorSpeed - joint.axis.velocity(a, b))
+
+		maxImpulse := joint.MaxMotorForce * joint.dt
+		accumulated := clamp(joint.motorImpulse+impulse, -maxImpulse, maxImpulse)
+		impulse, joint.motorImpulse = accumulated-joint.motorImpulse, accumulated
+
+		joint.axis.applyImpulse(a, b, impulse)
+	}
+
+	if joint.EnableLimit {
+		// just like the revolute joint, the limits only push the joint back into range
+		lowerGap := joint.translation - joint.LowerTranslation/neonMath.Metre
+		impulse := -joint.axialMass * (joint.axis.velocity(a, b) + math.Max(lowerGap, 0)/joint.dt)
+		accumulated := math.Max(joint.lowerImpulse+impulse, 0)
+		impulse, joint.lowerImpulse = accumulated-joint.lowerImpulse, accumulated
+		joint.axis.applyImpulse(a, b, impulse)
+
+		upperGap := joint.UpperTranslation/neonMath.Metre - joint.translation
+		impulse = -joint.axialMass * (-joint.axis.velocity(a, b) + math.Max(upperGap, 0)/joint.dt)
+		accumulated = math.Max(joint.upperImpulse+impulse, 0)
+		impulse, joint.upperImpulse = accumulated-joint.upperImpulse, accumulated
+		joint.axis.applyImpulse(a, b, -impulse)
+	}
+
+	// finally stop the bodies moving off the axis or rotating relative to each other
+	velocity := neonMath.Vector2D{X: joint.perpendicular.velocity(a, b), Y: b.state.AngularVelocity - a.state.AngularVelocity}
+	impulse := solveMatrix(joint.mass, velocity.Scale(-1))
+	joint.impulse = joint.impulse.Add(impulse)
+
+	joint.perpendicular.applyImpulse(a, b, impulse.X)
+	a.applyAngularImpulse(-impulse.Y)
+	b.applyAngularImpulse(impulse.Y)
+}
+
+func (joint *PrismaticJoint) solvePositions() {
+	a, b := joint.solverBodies()
+	_, perpendicular := newAxialConstraints(a, b, joint.LocalAxisA)
+
+	error := neonMath.Vector2D{
+		X: clamp(perpendicular.translation(a, b), -maxJointCorrection, maxJointCorrection),
+		Y: clamp(joint.BodyB.GetState().Angle-joint.BodyA.GetState().Angle-joint.ReferenceAngle, -maxJointAngularCorrection, maxJointAngularCorrection),
+	}
+	impulse := solveMatrix(perpendicular.angularMass(a, b), error.Scale(-1))
+	perpendicular.applyPositionImpulse(a, b, impulse.X)
+	a.applyAngularPositionImpulse(-impulse.Y)
+	b.applyAngularPositionImpulse(impulse.Y)
+
+	if joint.EnableLimit {
+		a, b = joint.solverBodies()
+		axis, _ := newAxialConstraints(a, b, joint.LocalAxisA)
+		translation, error := axis.translation(a, b), 0.0
+		lower, upper := joint.LowerTranslation/neonMath.Metre, joint.UpperTranslation/neonMath.Metre
+
+		switch {
+		case upper-lower < 2*linearSlop:
+			error = clamp(translation-lower, -maxJointCorrection, maxJointCorrection)
+		case translation <= lower:
+			error = clamp(translation-lower+linearSlop, -maxJointCorrection, 0)
+		case translation >= upper:
+			error = clamp(translation-upper-linearSlop, 0, maxJointCorrection)
+		}
+		axis.applyPositionImpulse(a, b, -inverse(axis.inverseMass(a, b))*error)
+	}
+}
+
+// WheelJoint lets a wheel (body b) spin freely while it slides along a sprung axis attached to the chassis (body a), this is a car suspension
+// the wheel can also be driven by a motor
+type WheelJoint struct {
+	JointBodies
+
+	LocalAxisA neonMath.Vector2D // the axis the suspension moves along, relative to body a
+
+	// The suspension is a spring along the axis, it rests when the anchors line up
+	Stiffness float64 // in N/m, the wheel slides freely along the axis if this is 0
+	Damping   float64 // in Ns/m
+
+	// The motor tries to turn the wheel at MotorSpeed (in rad/s) using at most MaxMotorTorque (in Nm)
+	EnableMotor    bool
+	MotorSpeed     float64
+	MaxMotorTorque float64
+
+	a, b                jointBody
+	axis, perpendicular axialConstraint
+	mass, motorMass     float64
+	springMass          float64
+	bias, gamma, dt     float64
+
+	impulse, springImpulse, motorImpulse float64
+}
+
+// NewWheelJoint attaches a wheel to a chassis at the anchor (in world coordinates, usually the centre of the wheel), the suspension moves along the axis (also in world coordinates)
+func NewWheelJoint(chassis, wheel entities.Body, anchor, axis neonMath.Vector2D) *WheelJoint {
+	return &WheelJoint{
+		JointBodies: JointBodies{BodyA: chassis, BodyB: wheel, LocalAnchorA: localAnchor(chassis, anchor), LocalAnchorB: localAnchor(wheel, anchor)},
+		LocalAxisA:  axis.Normalise().Rotate(-chassis.GetState().Angle),
+	}
+}
+
+// Translation returns how far (in world units) the suspension has been compressed (or stretched) along its axis
+func (joint *WheelJoint) Translation() float64 {
+	return jointTranslation(&joint.JointBodies, joint.LocalAxisA)
+}
+
+func (joint *WheelJoint) prepare(config SolverConfig, dt float64) {
+	joint.a, joint.b = joint.solverBodies()
+	joint.axis, joint.perpendicular = newAxialConstraints(joint.a, joint.b, joint.LocalAxisA)
+	joint.mass = inverse(joint.perpendicular.inverseMass(joint.a, joint.b))
+	joint.motorMass = inverse(joint.a.invInertia + joint.b.invInertia)
+	joint.dt = dt
+
+	joint.springMass, joint.bias, joint.gamma = 0, 0, 0
+	if invMass := joint.axis.inverseMass(joint.a, joint.b); joint.Stiffness > 0 && invMass > 0 {
+		joint.gamma, joint.bias = softConstraint(joint.Stiffness, joint.Damping, joint.axis.translation(joint.a, joint.b), dt)
+		joint.springMass = inverse(invMass + joint.gamma)
+	}
+
+	if joint.Stiffness <= 0 || !config.WarmStarting {
+		joint.springImpulse = 0
+	}
+	if !joint.EnableMotor || !config.WarmStarting {
+		joint.motorImpulse = 0
+	}
+	if !config.WarmStarting {
+		joint.impulse = 0
+	}
+
+	joint.perpendicular.applyImpulse(joint.a, joint.b, joint.impulse)
+	joint.axis.applyImpulse(joint.a, joint.b, joint.springImpulse)
+	joint.a.applyAngularImpulse(-joint.motorImpulse)
+	joint.b.applyAngularImpulse(joint.motorImpulse)
+}
+
+func (joint *WheelJoint) solveVelocities() {
+	a, b := joint.a, joint.b
+
+	impulse := -joint.springMass * (joint.axis.velocity(a, b) + joint.bias + joint.gamma*joint.springImpulse)
+	joint.springImpulse += impulse
+	joint.axis.applyImpulse(a, b, impulse)
+
+	if joint.EnableMotor {
+		impulse = -joint.motorMass * (b.state.AngularVelocity - a.state.AngularVelocity - joint.MotorSpeed)
+
+		maxImpulse := joint.MaxMotorTorque * joint.dt
+		accumulated := clamp(joint.motorImpulse+impulse, -maxImpulse, maxImpulse)
+		impulse, joint.motorImpulse = accumulated-joint.motorImpulse, accumulated
+
+		a.applyAngularImpulse(-impulse)
+		b.applyAngularImpulse(impulse)
+	}
+
+	// finally keep the wheel on the axis
+	impulse = -joint.mass * joint.perpendicular.velocity(a, b)
+	joint.impulse += impulse
+	joint.perpendicular.applyImpulse(a, b, impulse)
+}
+
+func (joint *WheelJoint) solvePositions() {
+	a, b := joint.solverBodies()
+	_, perpendicular := newAxialConstraints(a, b, joint.LocalAxisA)
+
+	error := clamp(perpendicular.translation(a, b), -maxJointCorrection, maxJointCorrection)
+	perpendicular.applyPositionImpulse(a, b, -inverse(perpendicular.inverseMass(a, b))*error)
+}
+
+// axialConstraint constrains how far apart the anchors of two bodies are along an axis that is fixed to body a, since the axis turns with body a
+// the impulse has a lever arm about body a that reaches all the way out to the anchor of body b
+type axialConstraint struct {
+	axis       neonMath.Vector2D
+	armA, armB float64
+}
+
+// newAxialConstraints creates the constraints along the axis (relative to body a) and perpendicular to it
+func newAxialConstraints(a, b jointBody, localAxis neonMath.Vector2D) (axialConstraint, axialConstraint) {
+	axis := localAxis.Rotate(a.state.Angle)
+	return newAxialConstraint(a, b, axis), newAxialConstraint(a, b, axis.Normal())
+}
+
+func newAxialConstraint(a, b jointBody, axis neonMath.Vector2D) axialConstraint {
+	return axialConstraint{
+		axis: axis,
+		armA: b.anchor().Sub(a.state.CentroidPosition.Scale(1.0 / neonMath.Metre)).CrossMag(axis),
+		armB: b.r.CrossMag(axis),
+	}
+}
+
+// translation is how far apart the anchors are along the axis (in metres)
+func (constraint axialConstraint) translation(a, b jointBody) float64 {
+	return b.anchor().Sub(a.anchor()).Dot(constraint.axis)
+}
+
+// velocity is how fast the anchors are moving apart along the axis
+func (constraint axialConstraint) velocity(a, b jointBody) float64 {
+	return constraint.axis.Dot(b.state.Velocity.Sub(a.state.Velocity)) + constraint.armB*b.state.AngularVelocity - constraint.armA*a.state.AngularVelocity
+}
+
+// inverseMass computes the inverse of the mass "felt" by an impulse along the axis
+func (constraint axialConstraint) inverseMass(a, b jointBody) float64 {
+	return a.invMass + b.invMass + a.invInertia*constraint.armA*constraint.armA + b.invInertia*constraint.armB*constraint.armB
+}
+
+// angularMass computes the inverse of the mass matrix "felt" by an impulse along the axis combined with an angular impulse
+func (constraint axialConstraint) angularMass(a, b jointBody) neonMath.Matrix2 {
+	coupling := a.invInertia*constraint.armA + b.invInertia*constraint.armB
+	angular := a.invInertia + b.invInertia
+	if angular == 0 {
+		// neither body can rotate, so the angular part of the constraint just does nothing
+		angular = 1
+	}
+
+	return neonMath.Matrix2{M: [2][2]float64{
+		{constraint.inverseMass(a, b), coupling},
+		{coupling, angular},
+	}}
+}
+
+// applyImpulse applies an impulse along the axis to body b and the opposite impulse to body a
+func (constraint axialConstraint) applyImpulse(a, b jointBody, impulse float64) {
+	a.state.Velocity = a.state.Velocity.Sub(constraint.axis.Scale(impulse * a.invMass))
+	a.state.AngularVelocity -= a.invInertia * constraint.armA * impulse
+	b.state.Velocity = b.state.Velocity.Add(constraint.axis.Scale(impulse * b.invMass))
+	b.state.AngularVelocity += b.invInertia * constraint.armB * impulse
+}
+
+// applyPositionImpulse moves the bodies as if the impulse along the axis was applied for a second
+func (constraint axialConstraint) applyPositionImpulse(a, b jointBody, impulse float64) {
+	a.state.ShiftCentroid(constraint.axis.Scale(-impulse * a.invMass * neonMath.Metre))
+	a.body.Rotate(-a.invInertia * constraint.armA * impulse)
+	b.state.ShiftCentroid(constraint.axis.Scale(impulse * b.invMass * neonMath.Metre))
+	b.body.Rotate(b.invInertia * constraint.armB * impulse)
+}
+
+// jointTranslation is how far apart (in world units) the anchors of a joint are along an axis fixed to body a
+func jointTranslation(joint *JointBodies, localAxis neonMath.Vector2D) float64 {
+	anchorA, anchorB := joint.WorldAnchors()
+	return anchorB.Sub(anchorA).Dot(localAxis.Rotate(joint.BodyA.GetState().Angle))
+}
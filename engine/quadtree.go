@@ -0,0 +1,223 @@
+package engine
+
+import (
+	neonMath "Neon/engine/math"
+	"Neon/entities"
+)
+
+// quadtreeNode is a single region within the quadtree, bodies that straddle the boundaries between its children are stored at the node itself
+type quadtreeNode struct {
+	bounds   neonMath.AABB
+	depth    int
+	bodies   []entities.Body
+	children []*quadtreeNode // either nil (for leaves) or the 4 quadrants of the node
+}
+
+// Quadtree is a region quadtree, space is recursively divided into quadrants whenever a region holds too many bodies
+// Along with acting as a broadphase it doubles as a general purpose spatial index, games can query it for every body within a rectangle
+// Note: bodies that lie outside of the bounds of the tree are just stored at the root
+type Quadtree struct {
+	root         *quadtreeNode
+	maxDepth     int
+	nodeCapacity int
+
+	// located tracks which node every body lives in and boxes caches the bounding box of every body as of the last update
+	located map[entities.Body]*quadtreeNode
+	boxes   map[entities.Body]neonMath.AABB
+	// bodies is every body in the order they were inserted, updates walk this instead of the maps so the tree is built the same way every run
+	bodies []entities.Body
+}
+
+// NewQuadtree creates an empty quadtree covering bounds, a node is split into quadrants once it holds more than nodeCapacity bodies
+// and is no deeper than maxDepth
+func NewQuadtree(bounds neonMath.AABB, maxDepth, nodeCapacity int) *Quadtree {
+	return &Quadtree{
+		root:         &quadtreeNode{bounds: bounds},
+		maxDepth:     maxDepth,
+		nodeCapacity: nodeCapacity,
+		located:      make(map[entities.Body]*quadtreeNode),
+		boxes:        make(map[entities.Body]neonMath.AABB),
+	}
+}
+
+// Insert adds a body to the quadtree
+func (tree *Quadtree) Insert(body entities.Body) {
+	if _, exists := tree.located[body]; exists {
+		return
+	}
+
+	tree.boxes[body] = body.GetBoundingBox()
+	tree.bodies = append(tree.bodies, body)
+	tree.insert(tree.root, body)
+}
+
+// Remove removes a body from the quadtree
+func (tree *Quadtree) Remove(body entities.Body) {
+	node, exists := tree.located[body]
+	if !exists {
+		return
+	}
+
+	node.remove(body)
+	delete(tree.located, body)
+	delete(tree.boxes, body)
+	for i, b := range tree.bodies {
+		if b == body {
+			tree.bodies = append(tree.bodies[:i], tree.bodies[i+1:]...)
+			break
+		}
+	}
+	tree.collapse(tree.root)
+}
+
+// Update moves every body that has left its region (or can now fit into a smaller one) into the correct node
+func (tree *Quadtree) Update() {
+	for _, body := range tree.bodies {
+		node := tree.located[body]
+		box := body.GetBoundingBox()
+		tree.boxes[body] = box
+
+		// the body can stay put if it still fits in the node and couldnt be pushed any further down
+		if (node == tree.root || node.bounds.Contains(box)) && node.quadrantFor(box) == nil {
+			continue
+		}
+		node.remove(body)
+		tree.insert(tree.root, body)
+	}
+	tree.collapse(tree.root)
+}
+
+// ComputePairs returns every pair of bodies whose bounding boxes overlap
+func (tree *Quadtree) ComputePairs() []BodyPair {
+	pairs := []BodyPair{}
+	tree.computePairs(tree.root, []entities.Body{}, &pairs)
+	return pairs
+}
+
+// QueryAABB returns every body whose bounding box (as of the last update) overlaps the provided box
+func (tree *Quadtree) QueryAABB(box neonMath.AABB) []entities.Body {
+	bodies := []entities.Body{}
+
+	stack := []*quadtreeNode{tree.root}
+	for len(stack) != 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		for _, body := range node.bodies {
+			if tree.boxes[body].Overlaps(box) {
+				bodies = append(bodies, body)
+			}
+		}
+		for _, child := range node.children {
+			if child.bounds.Overlaps(box) {
+				stack = append(stack, child)
+			}
+		}
+	}
+	return bodies
+}
+
+// insert pushes the body as far down the tree as it can go, splitting nodes that have become too full
+func (tree *Quadtree) insert(node *quadtreeNode, body entities.Body) {
+	box := tree.boxes[body]
+	for {
+		quadrant := node.quadrantFor(box)
+		if quadrant == nil {
+			break
+		}
+		node = quadrant
+	}
+
+	node.bodies = append(node.bodies, body)
+	tree.located[body] = node
+
+	if node.children == nil && len(node.bodies) > tree.nodeCapacity && node.depth < tree.maxDepth {
+		tree.split(node)
+	}
+}
+
+// split divides a leaf into 4 quadrants and pushes down every body that fits in one of them
+func (tree *Quadtree) split(node *quadtreeNode) {
+	centre := node.bounds.Min.Add(node.bounds.Max).Scale(0.5)
+	min, max := node.bounds.Min, node.bounds.Max
+
+	node.children = []*quadtreeNode{
+		{bounds: neonMath.AABB{Min: min, Max: centre}, depth: node.depth + 1},
+		{bounds: neonMath.AABB{Min: neonMath.Vector2D{X: centre.X, Y: min.Y}, Max: neonMath.Vector2D{X: max.X, Y: centre.Y}}, depth: node.depth + 1},
+		{bounds: neonMath.AABB{Min: neonMath.Vector2D{X: min.X, Y: centre.Y}, Max: neonMath.Vector2D{X: centre.X, Y: max.Y}}, depth: node.depth + 1},
+		{bounds: neonMath.AABB{Min: centre, Max: max}, depth: node.depth + 1},
+	}
+
+	bodies := node.bodies
+	node.bodies = nil
+	for _, body := range bodies {
+		tree.insert(node, body)
+	}
+}
+
+// collapse merges the children of any node whose subtree has become small enough to fit within a single node, it returns the size of the subtree
+func (tree *Quadtree) collapse(node *quadtreeNode) int {
+	if node.children == nil {
+		return len(node.bodies)
+	}
+
+	count := len(node.bodies)
+	for _, child := range node.children {
+		count += tree.collapse(child)
+	}
+
+	if count <= tree.nodeCapacity {
+		for _, child := range node.children {
+			for _, body := range child.bodies {
+				node.bodies = append(node.bodies, body)
+				tree.located[body] = node
+			}
+		}
+		node.children = nil
+	}
+	return count
+}
+
+// computePairs reports all pairs within the subtree rooted at node, ancestors are all the bodies stored above the node (they could overlap anything below)
+func (tree *Quadtree) computePairs(node *quadtreeNode, ancestors []entities.Body, pairs *[]BodyPair) {
+	for i, a := range node.bodies {
+		boxA := tree.boxes[a]
+		for _, b := range node.bodies[i+1:] {
+			if boxA.Overlaps(tree.boxes[b]) {
+				*pairs = append(*pairs, BodyPair{A: a, B: b})
+			}
+		}
+		for _, b := range ancestors {
+			if boxA.Overlaps(tree.boxes[b]) {
+				*pairs = append(*pairs, BodyPair{A: b, B: a})
+			}
+		}
+	}
+
+	if node.children != nil {
+		ancestors = append(ancestors[:len(ancestors):len(ancestors)], node.bodies...)
+		for _, child := range node.children {
+			tree.computePairs(child, ancestors, pairs)
+		}
+	}
+}
+
+// quadrantFor returns the child of the node that completely contains the box, if there is no such child (or the node is a leaf) then nil is returned
+func (node *quadtreeNode) quadrantFor(box neonMath.AABB) *quadtreeNode {
+	for _, child := range node.children {
+		if child.bounds.Contains(box) {
+			return child
+		}
+	}
+	return nil
+}
+
+// remove removes a body from the list of bodies stored at the node
+func (node *quadtreeNode) remove(body entities.Body) {
+	for i, b := range node.bodies {
+		if b == body {
+			node.bodies = append(node.bodies[:i], node.bodies[i+1:]...)
+			return
+		}
+	}
+}
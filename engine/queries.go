@@ -0,0 +1,167 @@
+package engine
+
+import (
+	neonMath "Neon/engine/math"
+	"Neon/entities"
+	"math"
+	"sort"
+)
+
+/*
+	Queries let the outside world ask questions about the tracked bodies without having to step the simulation, every query is pruned
+	with the broadphase first and then with the bounding box of each body before running the exact (and more expensive) test
+*/
+
+// RayCastHit describes where a ray (or a cast shape) hit a body
+type RayCastHit struct {
+	Body     entities.Body
+	Point    neonMath.Vector2D
+	Normal   neonMath.Vector2D // outwards normal of the surface that was hit
+	Fraction float64           // how far along the ray (or cast) the hit was, 0 is the start and 1 is the end
+}
+
+// RayCast finds the closest body hit by a ray starting at origin and travelling at most maxDistance along direction
+func (receiver PhysicsManager) RayCast(origin, direction neonMath.Vector2D, maxDistance float64) (RayCastHit, bool) {
+	hits := receiver.RayCastAll(origin, direction, maxDistance)
+	if len(hits) == 0 {
+		return RayCastHit{}, false
+	}
+	return hits[0], true
+}
+
+// RayCastAll finds every body hit by a ray starting at origin and travelling at most maxDistance along direction, the hits are sorted from closest to furthest
+// Sensors dont block anything so rays pass straight through them
+func (receiver PhysicsManager) RayCastAll(origin, direction neonMath.Vector2D, maxDistance float64) []RayCastHit {
+	hits := []RayCastHit{}
+	if direction == neonMath.ZeroVec2D || maxDistance <= 0 {
+		return hits
+	}
+	direction = direction.Normalise()
+
+	end := origin.Add(direction.Scale(maxDistance))
+	rayBox := neonMath.AABB{
+		Min: neonMath.Vector2D{X: math.Min(origin.X, end.X), Y: math.Min(origin.Y, end.Y)},
+		Max: neonMath.Vector2D{X: math.Max(origin.X, end.X), Y: math.Max(origin.Y, end.Y)},
+	}
+
+	receiver.broadphase.Update()
+	for _, body := range receiver.broadphase.QueryAABB(rayBox) {
+		if body.GetState().Sensor || !body.GetBoundingBox().IntersectsRay(origin, direction, maxDistance) {
+			continue
+		}
+
+		if distance, normal, hit := body.RayCast(origin, direction, maxDistance); hit {
+			hits = append(hits, RayCastHit{
+				Body:     body,
+				Point:    origin.Add(direction.Scale(distance)),
+				Normal:   normal,
+				Fraction: distance / maxDistance,
+			})
+		}
+	}
+
+	sort.Slice(hits, func(i, j int) bool { return hits[i].Fraction < hits[j].Fraction })
+	return hits
+}
+
+// ShapeCast sweeps a polygon along translation and finds the first body it hits, the polygon itself is never moved
+// Bodies that the polygon already overlaps are hit straight away (with a fraction of 0), sensors are ignored just like they are for ray casts
+// If a compound body is hit then the point and normal come from the deepest of its shapes that the polygon touches
+func (receiver PhysicsManager) ShapeCast(shape *entities.Polygon, translation neonMath.Vector2D) (RayCastHit, bool) {
+	cast := *shape
+	box := cast.GetBoundingBox()
+
+	receiver.broadphase.Update()
+	candidates := []entities.Body{}
+	for _, body := range receiver.broadphase.QueryAABB(box.Union(box.Translate(translation))) {
+		if entities.Body(shape) == body || body.GetState().Sensor {
+			continue
+		}
+
+		if collides, manifold := DetermineCollision(&cast, body); collides {
+			return shapeCastHit(body, manifold, 0), true
+		}
+		candidates = append(candidates, body)
+	}
+
+	start := cast.State.CentroidPosition
+	fraction, hit := sweep(&cast, candidates, translation.Length(), func(fraction float64) {
+		cast.State.CentroidPosition = cast.State.CentroidPosition.Add(translation.Scale(fraction))
+	})
+	if !hit {
+		return RayCastHit{}, false
+	}
+
+	// the sweep leaves the shape just inside whatever it hit, this gives us a manifold to pull the point and normal from
+	cast.State.CentroidPosition = start.Add(translation.Scale(fraction))
+	for _, body := range candidates {
+		if collides, manifold := DetermineCollision(&cast, body); collides {
+			return shapeCastHit(body, manifold, fraction), true
+		}
+	}
+	return RayCastHit{}, false
+}
+
+// shapeCastHit builds a hit from the manifold between the cast shape and the body it hit
+func shapeCastHit(body entities.Body, manifold ContactManifold, fraction float64) RayCastHit {
+	// the MTV points away from the reference frame, the normal has to point out of the body that was hit
+	normal := manifold.MTV.Normalise()
+	if manifold.ReferenceFrame != body {
+		normal = normal.Scale(-1)
+	}
+
+	point := neonMath.ZeroVec2D
+	for _, p := range manifold.CollisionPoints {
+		point = point.Add(p)
+	}
+	if len(manifold.CollisionPoints) != 0 {
+		point = point.Scale(1.0 / float64(len(manifold.CollisionPoints)))
+	}
+
+	return RayCastHit{Body: body, Point: point, Normal: normal, Fraction: fraction}
+}
+
+// QueryPoint finds every body that contains the point
+func (receiver PhysicsManager) QueryPoint(point neonMath.Vector2D) []entities.Body {
+	bodies := []entities.Body{}
+
+	receiver.broadphase.Update()
+	for _, body := range receiver.broadphase.QueryAABB(neonMath.AABB{Min: point, Max: point}) {
+		if body.GetBoundingBox().Overlaps(neonMath.AABB{Min: point, Max: point}) && body.ContainsPoint(point) {
+			bodies = append(bodies, body)
+		}
+	}
+	return bodies
+}
+
+// QueryAABB finds every body that overlaps the box with corners min and max
+func (receiver PhysicsManager) QueryAABB(min, max neonMath.Vector2D) []entities.Body {
+	box := entities.NewPolygon([]neonMath.Vector2D{{X: min.X, Y: max.Y}, max, {X: max.X, Y: min.Y}, min})
+	return receiver.QueryShape(&box)
+}
+
+// QueryShape finds every body that overlaps the polygon, polygons are tested with SAT directly while every other mesh goes through the usual collision dispatch
+// A compound body is reported once no matter how many of its shapes the polygon overlaps
+func (receiver PhysicsManager) QueryShape(shape *entities.Polygon) []entities.Body {
+	bodies := []entities.Body{}
+	box := shape.GetBoundingBox()
+
+	receiver.broadphase.Update()
+	for _, body := range receiver.broadphase.QueryAABB(box) {
+		if body == entities.Body(shape) || !body.GetBoundingBox().Overlaps(box) {
+			continue
+		}
+
+		overlaps := false
+		if polygon, isPolygon := body.(*entities.Polygon); isPolygon {
+			overlaps = entities.SAT(*shape, *polygon).Length() > equalityTolerance
+		} else {
+			overlaps, _ = DetermineCollision(shape, body)
+		}
+
+		if overlaps {
+			bodies = append(bodies, body)
+		}
+	}
+	return bodies
+}
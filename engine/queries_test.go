@@ -0,0 +1,120 @@
+package engine
+
+import (
+	neonMath "Neon/engine/math"
+	"Neon/entities"
+	"math"
+	"testing"
+)
+
+// queryScene sets up a box, a circle and a capsule in a row along the x axis
+func queryScene() (PhysicsManager, []entities.Body) {
+	box := entities.NewPolygon([]neonMath.Vector2D{{X: 90, Y: 10}, {X: 110, Y: 10}, {X: 110, Y: -10}, {X: 90, Y: -10}})
+	circle := entities.NewCircle(neonMath.Vector2D{X: 200}, 10)
+	capsule := entities.NewCapsule(neonMath.Vector2D{X: 300, Y: -50}, neonMath.Vector2D{X: 300, Y: 50}, 10)
+
+	manager := NewPhysicsManager()
+	bodies := []entities.Body{&box, &circle, &capsule}
+	manager.BeginTracking(bodies...)
+	return manager, bodies
+}
+
+func TestRayCast(t *testing.T) {
+	manager, bodies := queryScene()
+
+	hit, hitAnything := manager.RayCast(neonMath.ZeroVec2D, neonMath.Vector2D{X: 1}, 400)
+	if !hitAnything || hit.Body != bodies[0] || math.Abs(hit.Fraction-90.0/400.0) > 1e-9 || hit.Normal != (neonMath.Vector2D{X: -1}) {
+		t.Errorf("expected to hit the left face of the box, got %+v", hit)
+	}
+
+	// every body should be hit in order, the ray starts inside the box so it doesnt count
+	hits := manager.RayCastAll(neonMath.Vector2D{X: 100}, neonMath.Vector2D{X: 1}, 400)
+	if len(hits) != 2 || hits[0].Body != bodies[1] || hits[1].Body != bodies[2] {
+		t.Fatalf("expected to hit the circle and then the capsule, got %+v", hits)
+	}
+	if hits[0].Point.Sub(neonMath.Vector2D{X: 190}).Length() > 1e-9 || hits[1].Point.Sub(neonMath.Vector2D{X: 290}).Length() > 1e-9 {
+		t.Errorf("expected hits at x = 190 and x = 290, got %v and %v", hits[0].Point, hits[1].Point)
+	}
+
+	// the rounded end of the capsule
+	hit, hitAnything = manager.RayCast(neonMath.Vector2D{X: 300, Y: 200}, neonMath.Vector2D{Y: -1}, 400)
+	if !hitAnything || hit.Body != bodies[2] || hit.Point.Sub(neonMath.Vector2D{X: 300, Y: 60}).Length() > 1e-9 {
+		t.Errorf("expected to hit the top of the capsule, got %+v", hit)
+	}
+
+	if _, hitAnything := manager.RayCast(neonMath.Vector2D{Y: 100}, neonMath.Vector2D{X: 1}, 400); hitAnything {
+		t.Errorf("the ray should have missed everything")
+	}
+
+	// straight into the bottom left corner of the box the left and bottom faces are hit at the same time, the same one should win every time
+	box := bodies[0].(*entities.Polygon)
+	diagonal := neonMath.Vector2D{X: 1, Y: 1}.Normalise()
+	_, first, _ := box.RayCast(neonMath.Vector2D{X: 80, Y: -20}, diagonal, 400)
+	for i := 0; i < 20; i++ {
+		if _, normal, _ := box.RayCast(neonMath.Vector2D{X: 80, Y: -20}, diagonal, 400); normal != first {
+			t.Fatalf("the same ray hit the corner with a normal of %v and then %v", first, normal)
+		}
+	}
+}
+
+func TestShapeCast(t *testing.T) {
+	manager, bodies := queryScene()
+
+	// sweep a small box along the x axis, it should stop at the left face of the big box
+	shape := entities.NewPolygon([]neonMath.Vector2D{{X: -5, Y: 5}, {X: 5, Y: 5}, {X: 5, Y: -5}, {X: -5, Y: -5}})
+	hit, hitAnything := manager.ShapeCast(&shape, neonMath.Vector2D{X: 400})
+	if !hitAnything || hit.Body != bodies[0] || math.Abs(hit.Fraction*400-85) > toiTolerance || hit.Normal.Sub(neonMath.Vector2D{X: -1}).Length() > 1e-6 {
+		t.Errorf("expected the shape to hit the box after moving 85 units, got %+v", hit)
+	}
+	if shape.State.CentroidPosition != neonMath.ZeroVec2D {
+		t.Errorf("the shape itself shouldnt move, its at %v", shape.State.CentroidPosition)
+	}
+
+	if _, hitAnything := manager.ShapeCast(&shape, neonMath.Vector2D{Y: 400}); hitAnything {
+		t.Errorf("the shape should have missed everything")
+	}
+}
+
+func TestCastsIgnoreSensors(t *testing.T) {
+	manager, bodies := queryScene()
+
+	// a trigger zone sitting between the origin and the box
+	sensor := entities.NewPolygon([]neonMath.Vector2D{{X: 40, Y: 20}, {X: 60, Y: 20}, {X: 60, Y: -20}, {X: 40, Y: -20}})
+	sensor.State.Sensor = true
+	manager.BeginTracking(&sensor)
+
+	if hit, hitAnything := manager.RayCast(neonMath.ZeroVec2D, neonMath.Vector2D{X: 1}, 400); !hitAnything || hit.Body != bodies[0] {
+		t.Errorf("the ray should have passed through the sensor and hit the box, got %+v", hit)
+	}
+	for _, hit := range manager.RayCastAll(neonMath.ZeroVec2D, neonMath.Vector2D{X: 1}, 400) {
+		if hit.Body == &sensor {
+			t.Errorf("the sensor shouldnt be reported as a hit")
+		}
+	}
+
+	shape := entities.NewPolygon([]neonMath.Vector2D{{X: -5, Y: 5}, {X: 5, Y: 5}, {X: 5, Y: -5}, {X: -5, Y: -5}})
+	if hit, hitAnything := manager.ShapeCast(&shape, neonMath.Vector2D{X: 400}); !hitAnything || hit.Body != bodies[0] {
+		t.Errorf("the shape should have passed through the sensor and hit the box, got %+v", hit)
+	}
+}
+
+func TestOverlapQueries(t *testing.T) {
+	manager, bodies := queryScene()
+
+	if found := manager.QueryPoint(neonMath.Vector2D{X: 300, Y: 55}); len(found) != 1 || found[0] != bodies[2] {
+		t.Errorf("expected the point to be within the capsule, got %v", found)
+	}
+	if found := manager.QueryPoint(neonMath.Vector2D{X: 150}); len(found) != 0 {
+		t.Errorf("expected the point to be within nothing, got %v", found)
+	}
+
+	// the box covers the circle and a corner of the big box, it stops just short of the capsule
+	if found := manager.QueryAABB(neonMath.Vector2D{X: 105, Y: -100}, neonMath.Vector2D{X: 285, Y: 5}); len(found) != 2 {
+		t.Errorf("expected the box to overlap the box and circle, got %v", found)
+	}
+
+	triangle := entities.NewPolygon([]neonMath.Vector2D{{X: 250, Y: 0}, {X: 350, Y: 100}, {X: 350, Y: -100}})
+	if found := manager.QueryShape(&triangle); len(found) != 1 || found[0] != bodies[2] {
+		t.Errorf("expected the triangle to overlap the capsule, got %v", found)
+	}
+}
@@ -0,0 +1,135 @@
+package engine
+
+import (
+	neonMath "Neon/engine/math"
+	"Neon/entities"
+	"math"
+)
+
+// RevoluteJoint pins two bodies together at a shared anchor, they are free to rotate relative to each other about it (like a hinge)
+// the rotation can be limited to a range of angles and driven by a motor
+type RevoluteJoint struct {
+	JointBodies
+
+	ReferenceAngle float64 // the angle of body b relative to body a (in radians) when the joint is at 0
+
+	// Limits on the angle of the joint (in radians)
+	EnableLimit            bool
+	LowerAngle, UpperAngle float64
+
+	// The motor tries to turn the joint at MotorSpeed (in rad/s) using at most MaxMotorTorque (in Nm)
+	EnableMotor    bool
+	MotorSpeed     float64
+	MaxMotorTorque float64
+
+	a, b      jointBody
+	pointMass neonMath.Matrix2
+	axialMass float64
+	angle, dt float64
+
+	impulse                    neonMath.Vector2D
+	motorImpulse               float64
+	lowerImpulse, upperImpulse float64
+}
+
+// NewRevoluteJoint pins the bodies together at the anchor (in world coordinates), the joint starts out at an angle of 0
+func NewRevoluteJoint(bodyA, bodyB entities.Body, anchor neonMath.Vector2D) *RevoluteJoint {
+	return &RevoluteJoint{
+		JointBodies:    JointBodies{BodyA: bodyA, BodyB: bodyB, LocalAnchorA: localAnchor(bodyA, anchor), LocalAnchorB: localAnchor(bodyB, anchor)},
+		ReferenceAngle: bodyB.GetState().Angle - bodyA.GetState().Angle,
+	}
+}
+
+// Angle returns the current angle of the joint (in radians)
+func (joint *RevoluteJoint) Angle() float64 {
+	return joint.BodyB.GetState().Angle - joint.BodyA.GetState().Angle - joint.ReferenceAngle
+}
+
+// Speed returns how fast the joint is currently turning (in rad/s)
+func (joint *RevoluteJoint) Speed() float64 {
+	return joint.BodyB.GetState().AngularVelocity - joint.BodyA.GetState().AngularVelocity
+}
+
+func (joint *RevoluteJoint) prepare(config SolverConfig, dt float64) {
+	joint.a, joint.b = joint.solverBodies()
+	joint.pointMass = pointMass(joint.a, joint.b)
+	joint.axialMass = inverse(joint.a.invInertia + joint.b.invInertia)
+	joint.angle, joint.dt = joint.Angle(), dt
+
+	// anything that has been switched off shouldnt keep its impulse around
+	if !joint.EnableMotor || !config.WarmStarting {
+		joint.motorImpulse = 0
+	}
+	if !joint.EnableLimit || !config.WarmStarting {
+		joint.lowerImpulse, joint.upperImpulse = 0, 0
+	}
+	if !config.WarmStarting {
+		joint.impulse = neonMath.ZeroVec2D
+	}
+
+	applyJointImpulse(joint.a, joint.b, joint.impulse)
+	axialImpulse := joint.motorImpulse + joint.lowerImpulse - joint.upperImpulse
+	joint.a.applyAngularImpulse(-axialImpulse)
+	joint.b.applyAngularImpulse(axialImpulse)
+}
+
+func (joint *RevoluteJoint) solveVelocities() {
+	a, b := joint.a, joint.b
+
+	if joint.EnableMotor {
+		impulse := -joint.axialMass * (b.state.AngularVelocity - a.state.AngularVelocity - joint.MotorSpeed)
+
+		// the motor can only apply so much torque within the timestep
+		maxImpulse := joint.MaxMotorTorque * joint.dt
+		accumulated := clamp(joint.motorImpulse+impulse, -maxImpulse, maxImpulse)
+		impulse, joint.motorImpulse = accumulated-joint.motorImpulse, accumulated
+
+		a.applyAngularImpulse(-impulse)
+		b.applyAngularImpulse(impulse)
+	}
+
+	if joint.EnableLimit {
+		// each limit can only push the joint back into range, while the joint is within range they are allowed to close whatever gap is left within this timestep
+		lowerGap := joint.angle - joint.LowerAngle
+		impulse := -joint.axialMass * (b.state.AngularVelocity - a.state.AngularVelocity + math.Max(lowerGap, 0)/joint.dt)
+		accumulated := math.Max(joint.lowerImpulse+impulse, 0)
+		impulse, joint.lowerImpulse = accumulated-joint.lowerImpulse, accumulated
+		a.applyAngularImpulse(-impulse)
+		b.applyAngularImpulse(impulse)
+
+		upperGap := joint.UpperAngle - joint.angle
+		impulse = -joint.axialMass * (a.state.AngularVelocity - b.state.AngularVelocity + math.Max(upperGap, 0)/joint.dt)
+		accumulated = math.Max(joint.upperImpulse+impulse, 0)
+		impulse, joint.upperImpulse = accumulated-joint.upperImpulse, accumulated
+		a.applyAngularImpulse(impulse)
+		b.applyAngularImpulse(-impulse)
+	}
+
+	// finally keep the anchors together
+	impulse := solveMatrix(joint.pointMass, b.velocity().Sub(a.velocity()).Scale(-1))
+	joint.impulse = joint.impulse.Add(impulse)
+	applyJointImpulse(a, b, impulse)
+}
+
+func (joint *RevoluteJoint) solvePositions() {
+	if joint.EnableLimit {
+		a, b := joint.solverBodies()
+		angle, error := joint.Angle(), 0.0
+
+		switch {
+		case joint.UpperAngle-joint.LowerAngle < 2*angularSlop:
+			error = clamp(angle-joint.LowerAngle, -maxJointAngularCorrection, maxJointAngularCorrection)
+		case angle <= joint.LowerAngle:
+			error = clamp(angle-joint.LowerAngle+angularSlop, -maxJointAngularCorrection, 0)
+		case angle >= joint.UpperAngle:
+			error = clamp(angle-joint.UpperAngle-angularSlop, 0, maxJointAngularCorrection)
+		}
+
+		impulse := -inverse(a.invInertia+b.invInertia) * error
+		a.applyAngularPositionImpulse(-impulse)
+		b.applyAngularPositionImpulse(impulse)
+	}
+
+	// the bodies have been rotated so the anchors have to be worked out again
+	solvePointPosition(joint.solverBodies())
+}
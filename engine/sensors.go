@@ -0,0 +1,78 @@
+package engine
+
+import "Neon/entities"
+
+/*
+	Sensors (or triggers) are bodies that only ever detect overlaps, they go through the exact same collision detection as everything else
+	but their manifolds are never resolved. Instead the manager keeps track of what is inside each sensor and reports whenever something enters or leaves
+*/
+
+// sensorCallbacks are invoked whenever a body begins or ends overlapping with a sensor
+type sensorCallbacks struct {
+	begin, end func(sensor, body entities.Body)
+}
+
+// AddSensorCallbacks registers callbacks for whenever a body starts overlapping a sensor and whenever it stops, either can be nil
+func (receiver *PhysicsManager) AddSensorCallbacks(begin, end func(sensor, body entities.Body)) {
+	receiver.sensorCallbacks = append(receiver.sensorCallbacks, sensorCallbacks{begin: begin, end: end})
+}
+
+// isSensor determines if either frame of the manifold is a sensor
+func (manifold ContactManifold) isSensor() bool {
+	return manifold.IncidentFrame.GetState().Sensor || manifold.ReferenceFrame.GetState().Sensor
+}
+
+// sensorPair orders the frames of a sensor manifold so that the sensor always comes first
+func sensorPair(manifold ContactManifold) BodyPair {
+	if manifold.ReferenceFrame.GetState().Sensor {
+		return BodyPair{A: manifold.ReferenceFrame, B: manifold.IncidentFrame}
+	}
+	return BodyPair{A: manifold.IncidentFrame, B: manifold.ReferenceFrame}
+}
+
+// updateSensorOverlaps compares the sensor overlaps in the manifolds against the ones from last time and fires the begin and end events accordingly
+func (receiver PhysicsManager) updateSensorOverlaps(manifolds []ContactManifold) {
+	current := make(map[BodyPair]bool)
+	for _, manifold := range manifolds {
+		if !manifold.isSensor() {
+			continue
+		}
+
+		pair := sensorPair(manifold)
+		current[pair] = true
+		if !receiver.sensorOverlaps[pair] {
+			receiver.sensorOverlaps[pair] = true
+			receiver.fireSensorEvent(pair, true)
+		}
+	}
+
+	for pair := range receiver.sensorOverlaps {
+		if !current[pair] {
+			delete(receiver.sensorOverlaps, pair)
+			receiver.fireSensorEvent(pair, false)
+		}
+	}
+}
+
+// removeSensorOverlaps ends every overlap involving the body, this is used when the body stops being tracked
+func (receiver PhysicsManager) removeSensorOverlaps(body entities.Body) {
+	for pair := range receiver.sensorOverlaps {
+		if pair.A == body || pair.B == body {
+			delete(receiver.sensorOverlaps, pair)
+			receiver.fireSensorEvent(pair, false)
+		}
+	}
+}
+
+func (receiver PhysicsManager) fireSensorEvent(pair BodyPair, begin bool) {
+	for _, callbacks := range receiver.sensorCallbacks {
+		callback := callbacks.end
+		if begin {
+			callback = callbacks.begin
+		}
+
+		if callback != nil {
+			callback(pair.A, pair.B)
+		}
+	}
+}
@@ -6,85 +6,432 @@ import (
 	"math"
 )
 
-// ResolveCollision computes what has to be done during a collision and resolves/calculates all the physics involved with it, given a collision manifold
-func (manifold ContactManifold) ResolveCollision() {
-	incidentFrame, referenceFrame := manifold.IncidentFrame, manifold.ReferenceFrame
+/*
+	The contact solver is a sequential impulse solver: every contact for the timestep is gathered up front and then each contact point is
+	solved one after the other, over and over again, until the impulses settle down. The impulses are accumulated over the iterations and it is the
+	accumulated impulse that gets clamped (a contact can only ever push) which is what stops stacks from jittering
+*/
+
+// MixingRules determines how the materials of the two frames are combined at a contact
+type MixingRules struct {
+	Restitution entities.MixingRule
+	Friction    entities.MixingRule
+}
+
+// DefaultMixingRules are used unless told otherwise, the bounciest material wins and friction is the average of the two
+var DefaultMixingRules = MixingRules{Restitution: entities.MixMax, Friction: entities.MixAverage}
+
+// PositionCorrection is the strategy used to push apart bodies that are still overlapping after the velocities have been solved
+type PositionCorrection int
+
+const (
+	// BaumgarteCorrection feeds a fraction of the penetration back into the velocity constraints, its cheap but it adds energy to the system
+	BaumgarteCorrection PositionCorrection = iota
+	// SplitImpulseCorrection solves for a separate "pseudo velocity" that is only used to move the bodies apart and then thrown away
+	SplitImpulseCorrection
+)
+
+// SolverConfig configures the contact solver
+type SolverConfig struct {
+	VelocityIterations int
+	PositionIterations int // used by joints and split impulse correction
+	PositionCorrection PositionCorrection
+
+	Baumgarte            float64 // fraction of the penetration that is corrected every timestep
+	Slop                 float64 // penetration (in world units) that is allowed to remain, this stops resting contacts from flickering
+	RestitutionThreshold float64 // contacts approaching slower than this (in m/s) dont bounce
+	WarmStarting         bool    // start each timestep from the impulses of the last one
+}
+
+// DefaultSolverConfig is used unless told otherwise
+var DefaultSolverConfig = SolverConfig{
+	VelocityIterations: 8,
+	PositionIterations: 3,
+	PositionCorrection: SplitImpulseCorrection,
+
+	Baumgarte:            0.2,
+	Slop:                 0.01 * neonMath.Metre,
+	RestitutionThreshold: 0.1,
+	WarmStarting:         true,
+}
+
+// contactPointConstraint is the constraint associated with a single contact point within a manifold, all vectors here are in metres
+type contactPointConstraint struct {
+	id          ContactID
+	rI, rR      neonMath.Vector2D // offsets of the contact from the centroids of the incident and reference frames
+	normalMass  float64
+	tangentMass float64
+	depth       float64
+
+	velocityBias float64 // the separation velocity that we are aiming for (due to restitution)
+
+	// Accumulated impulses
+	normalImpulse   float64
+	tangentImpulse  float64
+	positionImpulse float64
+}
+
+// contactConstraint is the set of constraints generated by a single manifold
+type contactConstraint struct {
+	manifold            *ContactManifold
+	incident, reference *entities.EntityState
+
+	invMassI, invInertiaI float64
+	invMassR, invInertiaR float64
+
+	normal, tangent neonMath.Vector2D
+	friction        float64
+	points          []*contactPointConstraint
+}
+
+// ContactImpulse is the accumulated impulse that was applied at a contact point, along the normal and the tangent of the contact
+type ContactImpulse struct {
+	Normal, Tangent float64
+}
+
+// impulseCache remembers the impulses applied at every contact point of every pair of frames during the last timestep
+// contacts are matched up by their IDs, so a contact that persists across timesteps can start from where it left off
+type impulseCache map[BodyPair]map[ContactID]ContactImpulse
 
-	if !incidentFrame.State.NoKinetic || !referenceFrame.State.NoKinetic {
-		// First solve the collision by fetching a solver and invoking it
-		manifold.getSolver()(incidentFrame, referenceFrame)
+// Contact is a manifold along with the settings that it is going to be solved with, the pre-solve hook is free to change any of these
+type Contact struct {
+	Manifold *ContactManifold
+	Enabled  bool // disabled contacts are just ignored by the solver
 
-		// Then statically resolve the collision
-		referenceFrame.State.ShiftCentroid(manifold.MTV.Scale(-1.0))
-		incidentFrame.State.ShiftCentroid(manifold.MTV)
+	Restitution     float64
+	StaticFriction  float64
+	DynamicFriction float64
+}
+
+// pseudoVelocity is the velocity used by split impulse to push overlapping bodies apart, it never ends up in the actual state of the body
+type pseudoVelocity struct {
+	velocity        neonMath.Vector2D
+	angularVelocity float64
+}
+
+// contactSolver solves all the contacts generated within a single timestep together
+type contactSolver struct {
+	config      SolverConfig
+	dt          float64
+	constraints []*contactConstraint
+	joints      []Joint
+
+	pseudoVelocities map[entities.Body]*pseudoVelocity
+}
+
+// newContactSolver builds the constraints for every manifold, if dt is 0 then no position correction is performed
+// preSolve (if it isnt nil) is given the chance to modify every contact before its constraints are built
+func newContactSolver(manifolds []ContactManifold, config SolverConfig, rules MixingRules, dt float64, preSolve func(contact *Contact)) *contactSolver {
+	solver := &contactSolver{
+		config:           config,
+		dt:               dt,
+		pseudoVelocities: make(map[entities.Body]*pseudoVelocity),
+	}
+
+	for i := range manifolds {
+		manifold := &manifolds[i]
+		if manifold.ContactCount == 0 || manifold.isSensor() {
+			continue
+		}
+
+		incident, reference := manifold.IncidentFrame.GetState(), manifold.ReferenceFrame.GetState()
+		if incident.Type != entities.DynamicBody && reference.Type != entities.DynamicBody {
+			continue
+		}
+
+		// the materials come from the shapes that were hit, so each part of a compound body can be made of something different
+		referenceShape, incidentShape := manifold.shapes()
+		incidentMaterial, referenceMaterial := incidentShape.GetState().Material, referenceShape.GetState().Material
+		contact := &Contact{
+			Manifold:        manifold,
+			Enabled:         true,
+			Restitution:     rules.Restitution.Mix(incidentMaterial.Restitution, referenceMaterial.Restitution),
+			StaticFriction:  rules.Friction.Mix(incidentMaterial.StaticFriction, referenceMaterial.StaticFriction),
+			DynamicFriction: rules.Friction.Mix(incidentMaterial.DynamicFriction, referenceMaterial.DynamicFriction),
+		}
+		if preSolve != nil {
+			preSolve(contact)
+		}
+		if !contact.Enabled || manifold.ContactCount == 0 {
+			continue
+		}
+
+		constraint := &contactConstraint{
+			manifold:  manifold,
+			incident:  incident,
+			reference: reference,
+			normal:    manifold.MTV.Normalise(),
+		}
+		constraint.tangent = constraint.normal.Normal()
+		constraint.invMassI, constraint.invInertiaI = inversePhysicalData(incident)
+		constraint.invMassR, constraint.invInertiaR = inversePhysicalData(reference)
+
+		sliding := false
+		for j, p := range manifold.CollisionPoints {
+			point := &contactPointConstraint{
+				id:    manifold.contactID(j),
+				rI:    p.Sub(incident.CentroidPosition).Scale(1.0 / neonMath.Metre),
+				rR:    p.Sub(reference.CentroidPosition).Scale(1.0 / neonMath.Metre),
+				depth: manifold.ContactDepths[j],
+			}
+			point.normalMass = constraint.effectiveMass(point, constraint.normal)
+			point.tangentMass = constraint.effectiveMass(point, constraint.tangent)
+
+			// Only contacts that are approaching fast enough bounce, otherwise resting contacts would never come to rest
+			relativeVelocity := constraint.relativeVelocity(point)
+			if separationVelocity := relativeVelocity.Dot(constraint.normal); separationVelocity < -config.RestitutionThreshold {
+				point.velocityBias = -contact.Restitution * separationVelocity
+			}
+			// the same threshold is used to decide if the surfaces are sliding past each other
+			if math.Abs(relativeVelocity.Dot(constraint.tangent)) > config.RestitutionThreshold {
+				sliding = true
+			}
+
+			constraint.points = append(constraint.points, point)
+		}
+
+		// surfaces that are already sliding past each other are subject to dynamic friction, everything else sticks with static friction
+		constraint.friction = contact.StaticFriction
+		if sliding {
+			constraint.friction = contact.DynamicFriction
+		}
+
+		solver.constraints = append(solver.constraints, constraint)
 	}
+
+	return solver
 }
 
-// Simple helper function for fetching the appropriate collision solver, will be expanded later to include more specialised solvers
-func (manifold *ContactManifold) getSolver() func(incidentFrame *entities.Polygon, referenceFrame *entities.Polygon) {
-	switch manifold.ContactCount {
-	case 1:
-		return manifold.resolvePointCollision
-	case 2:
-		return manifold.resolvePlanarCollision
+// warmStart applies the impulses from the last timestep to every contact that still exists, the solver then only has to correct them
+func (solver *contactSolver) warmStart(cache impulseCache) {
+	for _, constraint := range solver.constraints {
+		impulses, exists := cache[constraint.pair()]
+		if !exists {
+			continue
+		}
+
+		for _, point := range constraint.points {
+			if impulse, exists := impulses[point.id]; exists {
+				point.normalImpulse, point.tangentImpulse = impulse.Normal, impulse.Tangent
+				constraint.applyImpulse(point, constraint.normal.Scale(impulse.Normal).Add(constraint.tangent.Scale(impulse.Tangent)))
+			}
+		}
 	}
+}
 
-	return nil
+// impulses returns the accumulated impulses of every contact so they can be used to warm start the next timestep
+func (solver *contactSolver) impulses() impulseCache {
+	cache := impulseCache{}
+	for _, constraint := range solver.constraints {
+		impulses := make(map[ContactID]ContactImpulse, len(constraint.points))
+		for i, impulse := range constraint.impulses() {
+			impulses[constraint.points[i].id] = impulse
+		}
+		cache[constraint.pair()] = impulses
+	}
+	return cache
 }
 
-// Resolves collision with one contact point
-func (manifold *ContactManifold) resolvePointCollision(incidentFrame, referenceFrame *entities.Polygon) {
-	pIncident := manifold.CollisionPoints[0]
-	pReference := manifold.CollisionPoints[0]
+// postSolve hands every manifold that was solved to the hook along with the impulses that were applied at each of its contact points
+func (solver *contactSolver) postSolve(hook func(manifold ContactManifold, impulses []ContactImpulse)) {
+	for _, constraint := range solver.constraints {
+		hook(*constraint.manifold, constraint.impulses())
+	}
+}
 
-	impulse := manifold.resolveCollisionWithAppPoints(incidentFrame, referenceFrame, pIncident, pReference)
+// addJoints adds a set of joints to be solved alongside the contacts, joints are only ever solved when there is an actual timestep
+func (solver *contactSolver) addJoints(joints []Joint) {
+	if solver.dt == 0 {
+		return
+	}
 
-	// Apply the computed impulse
-	incidentFrame.State.ApplyImpulse(impulse, pIncident)
-	referenceFrame.State.ApplyImpulse(impulse.Scale(-1.0), pReference)
+	for _, joint := range joints {
+		joint.prepare(solver.config, solver.dt)
+		solver.joints = append(solver.joints, joint)
+	}
+}
+
+// solveVelocities iteratively solves the velocity constraints for every joint and contact point
+func (solver *contactSolver) solveVelocities() {
+	for iteration := 0; iteration < solver.config.VelocityIterations; iteration++ {
+		for _, joint := range solver.joints {
+			joint.solveVelocities()
+		}
+		for _, constraint := range solver.constraints {
+			for _, point := range constraint.points {
+				constraint.solveFriction(point)
+			}
+			for _, point := range constraint.points {
+				constraint.solveNormal(point, solver.baumgarteBias(point))
+			}
+		}
+	}
+}
+
+// correctPositions pushes apart any bodies that are still overlapping (only for split impulse correction) and then moves any bodies that drifted away from their joints back into place
+func (solver *contactSolver) correctPositions() {
+	if solver.dt == 0 {
+		return
+	}
+	solver.correctContactPositions()
+
+	for iteration := 0; iteration < solver.config.PositionIterations; iteration++ {
+		for _, joint := range solver.joints {
+			joint.solvePositions()
+		}
+	}
+}
+
+// correctContactPositions solves for the pseudo velocities that push apart overlapping bodies
+func (solver *contactSolver) correctContactPositions() {
+	if solver.config.PositionCorrection != SplitImpulseCorrection {
+		return
+	}
+
+	for iteration := 0; iteration < solver.config.PositionIterations; iteration++ {
+		for _, constraint := range solver.constraints {
+			pseudoI := solver.pseudoVelocity(constraint.manifold.IncidentFrame)
+			pseudoR := solver.pseudoVelocity(constraint.manifold.ReferenceFrame)
+
+			for _, point := range constraint.points {
+				vI := pseudoI.velocity.Add(point.rI.CrossUpwardsWithVec(pseudoI.angularVelocity))
+				vR := pseudoR.velocity.Add(point.rR.CrossUpwardsWithVec(pseudoR.angularVelocity))
+				separationVelocity := vI.Sub(vR).Dot(constraint.normal)
+
+				target := solver.config.Baumgarte / solver.dt * math.Max(0, point.depth-solver.config.Slop) / neonMath.Metre
+				impulse := point.normalMass * (target - separationVelocity)
+
+				// just like the normal impulse, position impulses can only ever push the bodies apart
+				accumulated := math.Max(point.positionImpulse+impulse, 0)
+				impulse, point.positionImpulse = accumulated-point.positionImpulse, accumulated
+
+				p := constraint.normal.Scale(impulse)
+				pseudoI.velocity = pseudoI.velocity.Add(p.Scale(constraint.invMassI))
+				pseudoI.angularVelocity += constraint.invInertiaI * point.rI.CrossMag(p)
+				pseudoR.velocity = pseudoR.velocity.Sub(p.Scale(constraint.invMassR))
+				pseudoR.angularVelocity -= constraint.invInertiaR * point.rR.CrossMag(p)
+			}
+		}
+	}
+
+	// Move everything along its pseudo velocity, the pseudo velocity is then thrown away
+	for body, pseudo := range solver.pseudoVelocities {
+		body.GetState().ShiftCentroid(pseudo.velocity.Scale(neonMath.Metre * solver.dt))
+		body.Rotate(pseudo.angularVelocity * solver.dt)
+	}
+}
+
+// baumgarteBias is the extra separation velocity the contact has to achieve when using Baumgarte correction
+func (solver *contactSolver) baumgarteBias(point *contactPointConstraint) float64 {
+	if solver.config.PositionCorrection != BaumgarteCorrection || solver.dt == 0 {
+		return 0
+	}
+	return solver.config.Baumgarte / solver.dt * math.Max(0, point.depth-solver.config.Slop) / neonMath.Metre
+}
+
+func (solver *contactSolver) pseudoVelocity(body entities.Body) *pseudoVelocity {
+	if _, exists := solver.pseudoVelocities[body]; !exists {
+		solver.pseudoVelocities[body] = &pseudoVelocity{}
+	}
+	return solver.pseudoVelocities[body]
+}
+
+// impulses returns the accumulated impulse at every contact point of the constraint
+func (constraint *contactConstraint) impulses() []ContactImpulse {
+	impulses := make([]ContactImpulse, len(constraint.points))
+	for i, point := range constraint.points {
+		impulses[i] = ContactImpulse{Normal: point.normalImpulse, Tangent: point.tangentImpulse}
+	}
+	return impulses
+}
+
+// pair identifies the shapes involved in the constraint, the reference shape always comes first
+// shapes are used rather than the frames since a compound body can have several of its shapes touching the same body
+func (constraint *contactConstraint) pair() BodyPair {
+	referenceShape, incidentShape := constraint.manifold.shapes()
+	return BodyPair{A: referenceShape, B: incidentShape}
+}
+
+// solveNormal applies the impulse along the normal required to stop the frames approaching each other (plus any bias)
+func (constraint *contactConstraint) solveNormal(point *contactPointConstraint, bias float64) {
+	separationVelocity := constraint.relativeVelocity(point).Dot(constraint.normal)
+	impulse := point.normalMass * (math.Max(point.velocityBias, bias) - separationVelocity)
+
+	// The accumulated impulse can never pull the frames together
+	accumulated := math.Max(point.normalImpulse+impulse, 0)
+	impulse, point.normalImpulse = accumulated-point.normalImpulse, accumulated
+
+	constraint.applyImpulse(point, constraint.normal.Scale(impulse))
+}
+
+// solveFriction applies the impulse along the tangent required to stop the frames sliding, clamped by the Coulomb friction cone
+func (constraint *contactConstraint) solveFriction(point *contactPointConstraint) {
+	tangentVelocity := constraint.relativeVelocity(point).Dot(constraint.tangent)
+	impulse := -point.tangentMass * tangentVelocity
+
+	maxFriction := constraint.friction * point.normalImpulse
+	accumulated := math.Max(-maxFriction, math.Min(point.tangentImpulse+impulse, maxFriction))
+	impulse, point.tangentImpulse = accumulated-point.tangentImpulse, accumulated
+
+	constraint.applyImpulse(point, constraint.tangent.Scale(impulse))
 }
 
-// Resolves collisions with two contact points
-func (manifold *ContactManifold) resolvePlanarCollision(incidentFrame, referenceFrame *entities.Polygon) {
-	pointA, pointB := manifold.CollisionPoints[0], manifold.CollisionPoints[1]
+// relativeVelocity computes the velocity of the incident frame relative to the reference frame at the contact point
+func (constraint *contactConstraint) relativeVelocity(point *contactPointConstraint) neonMath.Vector2D {
+	vI := constraint.incident.Velocity.Add(point.rI.CrossUpwardsWithVec(constraint.incident.AngularVelocity))
+	vR := constraint.reference.Velocity.Add(point.rR.CrossUpwardsWithVec(constraint.reference.AngularVelocity))
+	return vI.Sub(vR)
+}
 
-	// To prevent short circuit evaliation we create two variables as opposed to chaning the invocations with and statements
-	impulseOne := manifold.resolveCollisionWithAppPoints(incidentFrame, referenceFrame, pointA, pointA)
-	impulseTwo := manifold.resolveCollisionWithAppPoints(incidentFrame, referenceFrame, pointB, pointB)
+// applyImpulse applies an impulse to the incident frame at the contact point and the opposite impulse to the reference frame
+func (constraint *contactConstraint) applyImpulse(point *contactPointConstraint, impulse neonMath.Vector2D) {
+	constraint.incident.Velocity = constraint.incident.Velocity.Add(impulse.Scale(constraint.invMassI))
+	constraint.incident.AngularVelocity += constraint.invInertiaI * point.rI.CrossMag(impulse)
 
-	// Apply the computed impulses
-	incidentFrame.State.ApplyImpulse(impulseOne, pointA)
-	referenceFrame.State.ApplyImpulse(impulseOne.Scale(-1.0), pointA)
+	constraint.reference.Velocity = constraint.reference.Velocity.Sub(impulse.Scale(constraint.invMassR))
+	constraint.reference.AngularVelocity -= constraint.invInertiaR * point.rR.CrossMag(impulse)
+}
 
-	incidentFrame.State.ApplyImpulse(impulseTwo, pointB)
-	referenceFrame.State.ApplyImpulse(impulseTwo.Scale(-1.0), pointB)
+// effectiveMass computes the mass "felt" by an impulse along the axis at the contact point
+func (constraint *contactConstraint) effectiveMass(point *contactPointConstraint, axis neonMath.Vector2D) float64 {
+	rnI, rnR := point.rI.CrossMag(axis), point.rR.CrossMag(axis)
+	inverseMass := constraint.invMassI + constraint.invMassR + constraint.invInertiaI*rnI*rnI + constraint.invInertiaR*rnR*rnR
+	if inverseMass == 0 {
+		return 0
+	}
+	return 1.0 / inverseMass
 }
 
-// resolveCollisionWithAppPoint resolves a collision given the application point(s)
-func (manifold *ContactManifold) resolveCollisionWithAppPoints(incidentFrame, referenceFrame *entities.Polygon, pIncident neonMath.Vector2D, pReference neonMath.Vector2D) neonMath.Vector2D {
-	collisionNormal := manifold.MTV.Normalise()
+// inversePhysicalData returns the inverse mass and inverse inertia of an entity, entities with infinite (or no) mass cant be moved by the solver
+func inversePhysicalData(e *entities.EntityState) (float64, float64) {
+	mass, inertia := e.RetrievePhysicalData()
+	return inverse(mass), inverse(inertia)
+}
 
-	rI, rR := pIncident.Sub(incidentFrame.State.CentroidPosition).Scale(1.0/neonMath.Metre), pReference.Sub(referenceFrame.State.CentroidPosition).Scale(1.0/neonMath.Metre)
-	mR, iR := referenceFrame.State.RetrievePhysicalData()
-	mI, iI := incidentFrame.State.RetrievePhysicalData()
+// ResolveCollision computes what has to be done during a collision and resolves/calculates all the physics involved with it, given a collision manifold
+// The manifold is solved in isolation, the manager solves every manifold together which is far more stable
+func (manifold ContactManifold) ResolveCollision() {
+	manifold.ResolveCollisionWithRules(DefaultMixingRules)
+}
 
-	// Compute the velocities at the point of collision
-	vPi := incidentFrame.State.Velocity.Sub(rI.CrossUpwardsWithVec(incidentFrame.State.AngularVelocity))
-	vPr := referenceFrame.State.Velocity.Add(rR.CrossUpwardsWithVec(referenceFrame.State.AngularVelocity))
+// ResolveCollisionWithRules is just ResolveCollision but the materials of the frames are mixed according to the provided rules
+func (manifold ContactManifold) ResolveCollisionWithRules(rules MixingRules) {
+	newContactSolver([]ContactManifold{manifold}, DefaultSolverConfig, rules, 0, nil).solveVelocities()
+	manifold.separate()
+}
 
-	separationVelocity := vPi.Sub(vPr).Dot(collisionNormal)
-	if math.IsNaN(separationVelocity) {
-		return neonMath.ZeroVec2D
+// separate statically resolves the collision, the MTV is split between the frames in proportion to their inverse masses
+func (manifold ContactManifold) separate() {
+	if manifold.ContactCount == 0 || manifold.isSensor() {
+		return
 	}
 
-	// weird dampening of the separation velocity, seems to produce more "reasonable" results
-	// this number was just arrived at via some weird testing
-	restitution := 0.954
-	impulse := -(1.0 + restitution) * separationVelocity /
-		((1.0/mR + 1.0/mI) +
-			math.Pow(rI.CrossMag(collisionNormal), 2)/iI +
-			math.Pow(rR.CrossMag(collisionNormal), 2)/iR)
-	impulse /= float64(manifold.ContactCount)
+	invMassI, _ := inversePhysicalData(manifold.IncidentFrame.GetState())
+	invMassR, _ := inversePhysicalData(manifold.ReferenceFrame.GetState())
+	if invMassI+invMassR == 0 {
+		return
+	}
 
-	return collisionNormal.Scale(impulse)
+	manifold.IncidentFrame.GetState().ShiftCentroid(manifold.MTV.Scale(invMassI / (invMassI + invMassR)))
+	manifold.ReferenceFrame.GetState().ShiftCentroid(manifold.MTV.Scale(-invMassR / (invMassI + invMassR)))
 }
@@ -0,0 +1,293 @@
+package engine
+
+import (
+	neonMath "Neon/engine/math"
+	"Neon/entities"
+	"math"
+	"testing"
+)
+
+// groundFixture sets up a wide slab of static ground whose top surface sits along y = 0
+func groundFixture() *entities.Polygon {
+	ground := entities.NewPolygon([]neonMath.Vector2D{{X: -500, Y: 0}, {X: 500, Y: 0}, {X: 500, Y: -100}, {X: -500, Y: -100}})
+	ground.State.Type = entities.StaticBody
+	return &ground
+}
+
+// groundedBox sets up a box resting 2 units into some static ground, its two bottom corners are the contact points
+func groundedBox(velocity neonMath.Vector2D, angularVelocity float64) (*entities.Polygon, *entities.Polygon) {
+	ground := groundFixture()
+
+	box := entities.NewPolygon([]neonMath.Vector2D{{X: -50, Y: 98}, {X: 50, Y: 98}, {X: 50, Y: -2}, {X: -50, Y: -2}})
+	box.State.Mass, box.State.RotationalInertia = 1.0, 1.0
+	box.State.Velocity, box.State.AngularVelocity = velocity, angularVelocity
+
+	return &box, ground
+}
+
+// cornerVelocities computes the vertical velocity of the bottom corners of a grounded box
+func cornerVelocities(box *entities.Polygon) (float64, float64) {
+	state := box.GetState()
+	left := neonMath.Vector2D{X: -50, Y: -50}.Scale(1.0 / neonMath.Metre).CrossUpwardsWithVec(state.AngularVelocity)
+	right := neonMath.Vector2D{X: 50, Y: -50}.Scale(1.0 / neonMath.Metre).CrossUpwardsWithVec(state.AngularVelocity)
+
+	return state.Velocity.Add(left).Y, state.Velocity.Add(right).Y
+}
+
+func TestNormalImpulse(t *testing.T) {
+	// the box is already moving away from the ground so the contact should leave it alone
+	box, ground := groundedBox(neonMath.Vector2D{X: 0, Y: 1}, 0)
+	if collides, manifold := DetermineCollision(box, ground); collides {
+		manifold.ResolveCollision()
+	}
+	if box.State.Velocity != (neonMath.Vector2D{X: 0, Y: 1}) || box.State.AngularVelocity != 0 {
+		t.Errorf("separating bodies should not receive an impulse, got a velocity of %v and an angular velocity of %v", box.State.Velocity, box.State.AngularVelocity)
+	}
+
+	// spinning anticlockwise drives the left corner into the ground while the right one lifts off
+	box, ground = groundedBox(neonMath.ZeroVec2D, 1.0)
+	before, _ := cornerVelocities(box)
+	if collides, manifold := DetermineCollision(box, ground); collides {
+		manifold.ResolveCollision()
+	}
+	if after, _ := cornerVelocities(box); after <= before {
+		t.Errorf("the collision should slow the left corner down, it went from %v to %v", before, after)
+	}
+}
+
+func TestApplyImpulseLeverArm(t *testing.T) {
+	state := entities.EntityState{Mass: 1.0, RotationalInertia: 1.0}
+
+	// an impulse of 1 applied 2m away from the centroid should produce a torque impulse of 2
+	state.ApplyImpulse(neonMath.Vector2D{X: 0, Y: 1}, neonMath.Vector2D{X: 2 * neonMath.Metre, Y: 0})
+	if math.Abs(state.AngularVelocity-2.0) > 1e-9 {
+		t.Errorf("expected an angular velocity of 2, got %v", state.AngularVelocity)
+	}
+}
+
+// slidingBox sets up a box that has just landed on some static ground while sliding to the right
+func slidingBox(staticFriction, dynamicFriction float64) (*entities.Polygon, *entities.Polygon) {
+	ground := groundFixture()
+	ground.State.Material = entities.Material{StaticFriction: staticFriction, DynamicFriction: dynamicFriction}
+
+	box := entities.NewPolygon([]neonMath.Vector2D{{X: -50, Y: 98}, {X: 50, Y: 98}, {X: 50, Y: -2}, {X: -50, Y: -2}})
+	box.State.Mass, box.State.RotationalInertia = 1.0, 1.0
+	box.State.Velocity = neonMath.Vector2D{X: 1.0, Y: -1.0}
+	box.State.Material = entities.Material{StaticFriction: staticFriction, DynamicFriction: dynamicFriction}
+
+	return &box, ground
+}
+
+func TestFriction(t *testing.T) {
+	box, ground := slidingBox(0, 0)
+	if collides, manifold := DetermineCollision(box, ground); collides {
+		manifold.ResolveCollision()
+	}
+	if box.State.Velocity.X != 1.0 {
+		t.Errorf("a frictionless box should keep sliding, got a velocity of %v", box.State.Velocity)
+	}
+
+	box, ground = slidingBox(0.1, 0.05)
+	if collides, manifold := DetermineCollision(box, ground); collides {
+		manifold.ResolveCollision()
+	}
+	if box.State.Velocity.X >= 1.0 || box.State.Velocity.X <= 0 {
+		t.Errorf("dynamic friction should slow the box down without stopping it, got a velocity of %v", box.State.Velocity)
+	}
+
+	box, ground = slidingBox(2.0, 1.0)
+	if collides, manifold := DetermineCollision(box, ground); collides {
+		manifold.ResolveCollision()
+	}
+	if box.State.Velocity.X > 0.2 {
+		t.Errorf("static friction should stop the box sliding, got a velocity of %v", box.State.Velocity)
+	}
+}
+
+func TestMaterials(t *testing.T) {
+	// drops a ball made of the material onto concrete and returns the fastest it ever moves back up
+	rebound := func(material entities.Material) float64 {
+		ground := groundFixture()
+		ground.State.Material = entities.Concrete
+		ball := entities.NewCircle(neonMath.Vector2D{Y: 40}, 20)
+		ball.State.Material = material
+		entities.ComputeMass(&ball)
+		ball.State.Velocity = neonMath.Vector2D{Y: -4}
+
+		manager := NewPhysicsManager()
+		manager.SetGravity(neonMath.Vector2D{Y: -9.8})
+		manager.BeginTracking(ground, &ball)
+
+		fastest := 0.0
+		for i := 0; i < 30; i++ {
+			manager.NextTimeStep(1.0 / 60.0)
+			fastest = math.Max(fastest, ball.State.Velocity.Y)
+		}
+		return fastest
+	}
+
+	// slides a box made of the material along concrete and returns how far it got
+	slide := func(material entities.Material) float64 {
+		ground := groundFixture()
+		ground.State.Material = entities.Concrete
+		box := entities.NewPolygon([]neonMath.Vector2D{{X: -30, Y: 60}, {X: 30, Y: 60}, {X: 30, Y: 0}, {X: -30, Y: 0}})
+		box.State.Material = material
+		entities.ComputeMass(&box)
+		box.State.Velocity = neonMath.Vector2D{X: 2}
+
+		manager := NewPhysicsManager()
+		manager.SetGravity(neonMath.Vector2D{Y: -9.8})
+		manager.BeginTracking(ground, &box)
+		for i := 0; i < 60; i++ {
+			manager.NextTimeStep(1.0 / 60.0)
+		}
+		return box.State.CentroidPosition.X
+	}
+
+	if rubber, concrete := rebound(entities.Rubber), rebound(entities.Concrete); rubber <= concrete {
+		t.Errorf("a rubber ball should bounce higher than a concrete one, they rebounded at %v and %v", rubber, concrete)
+	}
+	if ice, rubber := slide(entities.Ice), slide(entities.Rubber); ice <= rubber {
+		t.Errorf("an ice block should slide further than a rubber one, they got to %v and %v", ice, rubber)
+	}
+}
+
+func TestRestingContact(t *testing.T) {
+	for _, correction := range []PositionCorrection{BaumgarteCorrection, SplitImpulseCorrection} {
+		config := DefaultSolverConfig
+		config.PositionCorrection = correction
+
+		box, ground := slidingBox(0.5, 0.5)
+		box.State.Velocity = neonMath.Vector2D{}
+		box.State.Material.Restitution = 0
+
+		manager := NewPhysicsManager()
+		manager.SetSolverConfig(config)
+		manager.SetGravity(neonMath.Vector2D{Y: -9.8})
+		manager.BeginTracking(box, ground)
+		for i := 0; i < 120; i++ {
+			manager.NextTimeStep(1.0 / 60.0)
+		}
+
+		// the box started 2 units into the ground, it should be pushed out to within the slop and stay there
+		if depth := 48 - box.State.CentroidPosition.Y; depth > 2*config.Slop || depth < -config.Slop {
+			t.Errorf("the box should rest on the ground, it ended up %v units deep (correction %v)", depth, correction)
+		}
+	}
+}
+
+func TestWarmStartedStack(t *testing.T) {
+	ground := groundFixture()
+	ground.State.Material = entities.Material{StaticFriction: 0.6, DynamicFriction: 0.4}
+
+	manager := NewPhysicsManager()
+	manager.SetGravity(neonMath.Vector2D{Y: -9.8})
+	manager.BeginTracking(ground)
+
+	boxes := []*entities.Polygon{}
+	for i := 0; i < 6; i++ {
+		y := float64(i) * 60
+		box := entities.NewPolygon([]neonMath.Vector2D{{X: -30, Y: y + 60}, {X: 30, Y: y + 60}, {X: 30, Y: y}, {X: -30, Y: y}})
+		box.State.Mass, box.State.RotationalInertia = 1.0, 0.0267
+		box.State.Material = ground.State.Material
+
+		boxes = append(boxes, &box)
+		manager.BeginTracking(&box)
+	}
+
+	for i := 0; i < 600; i++ {
+		manager.NextTimeStep(1.0 / 60.0)
+	}
+
+	// every box should still be sitting on top of the one below it, each contact is allowed to sink by the slop
+	for i, box := range boxes {
+		expected := neonMath.Vector2D{X: 0, Y: float64(i)*60 + 30 - float64(i+1)*DefaultSolverConfig.Slop}
+		if offset := box.State.CentroidPosition.Sub(expected).Length(); offset > 1 {
+			t.Errorf("box %v drifted %v units out of the stack, its at %v", i, offset, box.State.CentroidPosition)
+		}
+	}
+}
+
+func TestKinematicPlatform(t *testing.T) {
+	platform := entities.NewPolygon([]neonMath.Vector2D{{X: -300, Y: 0}, {X: 300, Y: 0}, {X: 300, Y: -20}, {X: -300, Y: -20}})
+	platform.State.Type = entities.KinematicBody
+	platform.State.Velocity = neonMath.Vector2D{Y: 1.0}
+	platform.State.Material = entities.Material{StaticFriction: 0.5, DynamicFriction: 0.5}
+
+	// the wall is off to the side so only the platform ever reaches it
+	wall := entities.NewPolygon([]neonMath.Vector2D{{X: 150, Y: 200}, {X: 300, Y: 200}, {X: 300, Y: 180}, {X: 150, Y: 180}})
+	wall.State.Type = entities.StaticBody
+
+	box, _ := slidingBox(0.5, 0.5)
+	box.State.Velocity = neonMath.Vector2D{}
+
+	manager := NewPhysicsManager()
+	manager.SetGravity(neonMath.Vector2D{Y: -9.8})
+	manager.BeginTracking(&platform, &wall, box)
+	for i := 0; i < 30; i++ {
+		manager.NextTimeStep(1.0 / 60.0)
+	}
+
+	// the platform should carry the box upwards while ignoring both gravity and the box
+	if platform.State.Velocity.Y != 1.0 || math.Abs(platform.State.CentroidPosition.Y-(-10+0.5*neonMath.Metre)) > 1e-6 {
+		t.Errorf("the kinematic platform should move with its own velocity, it is at %v moving at %v", platform.State.CentroidPosition, platform.State.Velocity)
+	}
+	if math.Abs(box.State.Velocity.Y-1.0) > 0.1 {
+		t.Errorf("the box should be carried up by the platform, its moving at %v", box.State.Velocity)
+	}
+
+	// the platform passes straight through the static wall
+	for i := 0; i < 48; i++ {
+		manager.NextTimeStep(1.0 / 60.0)
+	}
+	if collides, _ := DetermineCollision(&platform, &wall); !collides || wall.State.CentroidPosition.Y != 190 {
+		t.Errorf("the platform should overlap the unmoved wall, the wall is at %v", wall.State.CentroidPosition)
+	}
+}
+
+func TestBullets(t *testing.T) {
+	for _, bullet := range []bool{false, true} {
+		// a thin wall just like the ones in the examples
+		wall := entities.NewPolygon([]neonMath.Vector2D{{X: 299, Y: -200}, {X: 301, Y: -200}, {X: 301, Y: 200}, {X: 299, Y: 200}})
+		wall.State.Type = entities.StaticBody
+
+		projectile := entities.NewCircle(neonMath.ZeroVec2D, 5)
+		projectile.State.Velocity = neonMath.Vector2D{X: 100}
+		projectile.State.Material.Restitution = 0
+		projectile.State.Bullet = bullet
+
+		manager := NewPhysicsManager()
+		manager.BeginTracking(&wall, &projectile)
+		for i := 0; i < 10; i++ {
+			manager.NextTimeStep(1.0 / 60.0)
+		}
+
+		if passedThrough := projectile.State.CentroidPosition.X > 300; passedThrough == bullet {
+			t.Errorf("bullet: %v, the projectile ended up at %v", bullet, projectile.State.CentroidPosition)
+		}
+	}
+}
+
+func TestSensors(t *testing.T) {
+	zone := entities.NewPolygon([]neonMath.Vector2D{{X: -100, Y: 0}, {X: 100, Y: 0}, {X: 100, Y: -100}, {X: -100, Y: -100}})
+	zone.State.Type, zone.State.Sensor = entities.StaticBody, true
+
+	ball := entities.NewCircle(neonMath.Vector2D{Y: 50}, 10)
+	ball.State.Velocity = neonMath.Vector2D{Y: -3.0}
+
+	entered, left := 0, 0
+	manager := NewPhysicsManager()
+	manager.BeginTracking(&zone, &ball)
+	manager.AddSensorCallbacks(
+		func(sensor, body entities.Body) { entered++ },
+		func(sensor, body entities.Body) { left++ },
+	)
+
+	// the ball should pass straight through the sensor without being slowed down
+	for i := 0; i < 60; i++ {
+		manager.NextTimeStep(1.0 / 60.0)
+	}
+	if ball.State.Velocity.Y != -3.0 || entered != 1 || left != 1 {
+		t.Errorf("expected the ball to pass through the sensor, it has velocity %v and entered %v times and left %v times", ball.State.Velocity, entered, left)
+	}
+}
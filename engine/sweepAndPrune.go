@@ -0,0 +1,229 @@
+package engine
+
+import (
+	neonMath "Neon/engine/math"
+	"Neon/entities"
+	"sort"
+)
+
+// sapProxy is the broadphase's representation of a body
+type sapProxy struct {
+	body entities.Body
+	box  neonMath.AABB
+	id   int
+}
+
+// sapEndpoint is either the start or the end of a proxy's interval along a single axis
+type sapEndpoint struct {
+	proxy *sapProxy
+	value float64
+	isMin bool
+}
+
+// endpointLess orders endpoints along an axis, starts are placed before ends with the same value so touching boxes count as overlapping
+func endpointLess(a, b *sapEndpoint) bool {
+	return a.value < b.value || (a.value == b.value && a.isMin && !b.isMin)
+}
+
+// SweepAndPrune is a sort and sweep broadphase, the endpoints of every body's bounding box are kept sorted along both axes
+// Since bodies dont move much between frames the lists are almost sorted already, so they are re-sorted with insertion sort and
+// the overlapping pairs are updated incrementally as endpoints swap past each other
+type SweepAndPrune struct {
+	axes    [2][]*sapEndpoint
+	proxies []*sapProxy
+	lookup  map[entities.Body]*sapProxy
+	pairs   map[[2]int]BodyPair
+
+	addedCallbacks   []func(pair BodyPair)
+	removedCallbacks []func(pair BodyPair)
+
+	// internal var for assigning proxy IDs
+	nextID int
+}
+
+// NewSweepAndPrune creates an empty sweep and prune broadphase
+func NewSweepAndPrune() *SweepAndPrune {
+	return &SweepAndPrune{
+		lookup: make(map[entities.Body]*sapProxy),
+		pairs:  make(map[[2]int]BodyPair),
+	}
+}
+
+// AddPairCallbacks registers callbacks that are invoked whenever a pair starts or stops overlapping, either may be nil
+func (sap *SweepAndPrune) AddPairCallbacks(added, removed func(pair BodyPair)) {
+	if added != nil {
+		sap.addedCallbacks = append(sap.addedCallbacks, added)
+	}
+	if removed != nil {
+		sap.removedCallbacks = append(sap.removedCallbacks, removed)
+	}
+}
+
+// Insert adds a body to the broadphase, its endpoints are appended to the end of each axis and then sorted into place
+func (sap *SweepAndPrune) Insert(body entities.Body) {
+	if _, exists := sap.lookup[body]; exists {
+		return
+	}
+
+	proxy := &sapProxy{body: body, box: body.GetBoundingBox(), id: sap.nextID}
+	sap.nextID++
+	sap.lookup[body] = proxy
+	sap.proxies = append(sap.proxies, proxy)
+
+	for axis := range sap.axes {
+		min, max := axisBounds(proxy.box, axis)
+		sap.axes[axis] = append(sap.axes[axis], &sapEndpoint{proxy: proxy, value: min, isMin: true}, &sapEndpoint{proxy: proxy, value: max})
+		sap.sortAxis(axis)
+	}
+}
+
+// Remove removes a body from the broadphase, any pairs it was part of are reported as removed
+func (sap *SweepAndPrune) Remove(body entities.Body) {
+	proxy, exists := sap.lookup[body]
+	if !exists {
+		return
+	}
+
+	for axis, endpoints := range sap.axes {
+		remaining := endpoints[:0]
+		for _, endpoint := range endpoints {
+			if endpoint.proxy != proxy {
+				remaining = append(remaining, endpoint)
+			}
+		}
+		sap.axes[axis] = remaining
+	}
+	for _, other := range sap.proxies {
+		sap.removePair(proxy, other)
+	}
+
+	delete(sap.lookup, body)
+	for i, p := range sap.proxies {
+		if p == proxy {
+			sap.proxies = append(sap.proxies[:i], sap.proxies[i+1:]...)
+			break
+		}
+	}
+}
+
+// Update refreshes the endpoints of every body and re-sorts both axes
+func (sap *SweepAndPrune) Update() {
+	for _, proxy := range sap.proxies {
+		proxy.box = proxy.body.GetBoundingBox()
+	}
+	for axis, endpoints := range sap.axes {
+		for _, endpoint := range endpoints {
+			min, max := axisBounds(endpoint.proxy.box, axis)
+			endpoint.value = max
+			if endpoint.isMin {
+				endpoint.value = min
+			}
+		}
+		sap.sortAxis(axis)
+	}
+}
+
+// ComputePairs returns every pair of bodies whose bounding boxes overlap
+func (sap *SweepAndPrune) ComputePairs() []BodyPair {
+	keys := make([][2]int, 0, len(sap.pairs))
+	for key := range sap.pairs {
+		keys = append(keys, key)
+	}
+	// sort the pairs so that collisions are always resolved in the same order
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i][0] < keys[j][0] || (keys[i][0] == keys[j][0] && keys[i][1] < keys[j][1])
+	})
+
+	pairs := make([]BodyPair, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, sap.pairs[key])
+	}
+	return pairs
+}
+
+// QueryAABB returns every body whose bounding box (as of the last update) overlaps the provided box
+func (sap *SweepAndPrune) QueryAABB(box neonMath.AABB) []entities.Body {
+	bodies := []entities.Body{}
+
+	// sweep along the x axis until we pass the end of the box
+	for _, endpoint := range sap.axes[0] {
+		if endpoint.value > box.Max.X {
+			break
+		}
+		if endpoint.isMin && endpoint.proxy.box.Overlaps(box) {
+			bodies = append(bodies, endpoint.proxy.body)
+		}
+	}
+	return bodies
+}
+
+// sortAxis insertion sorts the endpoints along an axis, whenever a start passes an end (or vice versa) the pair has either begun or stopped overlapping
+func (sap *SweepAndPrune) sortAxis(axis int) {
+	endpoints := sap.axes[axis]
+
+	for i := 1; i < len(endpoints); i++ {
+		current := endpoints[i]
+		j := i - 1
+		for ; j >= 0 && endpointLess(current, endpoints[j]); j-- {
+			other := endpoints[j]
+
+			switch {
+			case current.isMin && !other.isMin:
+				// the start of current has moved into other, they only actually overlap if their boxes overlap along the other axis too
+				if current.proxy.box.Overlaps(other.proxy.box) {
+					sap.addPair(current.proxy, other.proxy)
+				}
+			case !current.isMin && other.isMin:
+				// the end of current has moved out of other
+				sap.removePair(current.proxy, other.proxy)
+			}
+			endpoints[j+1] = other
+		}
+		endpoints[j+1] = current
+	}
+}
+
+func (sap *SweepAndPrune) addPair(a, b *sapProxy) {
+	key := pairKey(a, b)
+	if _, exists := sap.pairs[key]; exists || a == b {
+		return
+	}
+
+	pair := BodyPair{A: a.body, B: b.body}
+	if a.id > b.id {
+		pair = BodyPair{A: b.body, B: a.body}
+	}
+	sap.pairs[key] = pair
+	for _, callback := range sap.addedCallbacks {
+		callback(pair)
+	}
+}
+
+func (sap *SweepAndPrune) removePair(a, b *sapProxy) {
+	key := pairKey(a, b)
+	pair, exists := sap.pairs[key]
+	if !exists {
+		return
+	}
+
+	delete(sap.pairs, key)
+	for _, callback := range sap.removedCallbacks {
+		callback(pair)
+	}
+}
+
+// pairKey generates a key for a pair of proxies that doesnt depend on their order
+func pairKey(a, b *sapProxy) [2]int {
+	if a.id > b.id {
+		return [2]int{b.id, a.id}
+	}
+	return [2]int{a.id, b.id}
+}
+
+// axisBounds returns the extent of a box along either the x (0) or y (1) axis
+func axisBounds(box neonMath.AABB, axis int) (float64, float64) {
+	if axis == 0 {
+		return box.Min.X, box.Max.X
+	}
+	return box.Min.Y, box.Max.Y
+}
@@ -17,3 +17,11 @@ func equalSet(a, b []int) bool {
 
 	return aExistsB(a, b) && aExistsB(b, a)
 }
+
+// maxInt is just math.Max for integers
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,99 @@
+package engine
+
+import (
+	neonMath "Neon/engine/math"
+	"Neon/entities"
+)
+
+// WeldJoint glues two bodies together at an anchor so that they move as one, giving it a stiffness makes the weld bend and stretch like a spring instead
+// Note that a compound body is a lot cheaper (and a lot stiffer) if the bodies never have to come apart
+type WeldJoint struct {
+	JointBodies
+
+	ReferenceAngle float64 // the angle of body b relative to body a (in radians)
+
+	// The anchors are pulled together by a spring in N/m (and Ns/m), the weld is rigid if the stiffness is 0
+	LinearStiffness float64
+	LinearDamping   float64
+	// The bodies are turned back to the reference angle by a spring in Nm/rad (and Nms/rad), the weld is rigid if the stiffness is 0
+	AngularStiffness float64
+	AngularDamping   float64
+
+	a, b                     jointBody
+	pointMass                neonMath.Matrix2
+	linearBias               neonMath.Vector2D
+	linearGamma              float64
+	angularMass, angularBias float64
+	angularGamma             float64
+
+	impulse        neonMath.Vector2D
+	angularImpulse float64
+}
+
+// NewWeldJoint welds the bodies together at the anchor (in world coordinates)
+func NewWeldJoint(bodyA, bodyB entities.Body, anchor neonMath.Vector2D) *WeldJoint {
+	return &WeldJoint{
+		JointBodies:    JointBodies{BodyA: bodyA, BodyB: bodyB, LocalAnchorA: localAnchor(bodyA, anchor), LocalAnchorB: localAnchor(bodyB, anchor)},
+		ReferenceAngle: bodyB.GetState().Angle - bodyA.GetState().Angle,
+	}
+}
+
+func (joint *WeldJoint) prepare(config SolverConfig, dt float64) {
+	joint.a, joint.b = joint.solverBodies()
+
+	joint.angularGamma, joint.angularBias = 0, 0
+	if joint.AngularStiffness > 0 {
+		angle := joint.b.state.Angle - joint.a.state.Angle - joint.ReferenceAngle
+		joint.angularGamma, joint.angularBias = softConstraint(joint.AngularStiffness, joint.AngularDamping, angle, dt)
+	}
+	joint.angularMass = inverse(joint.a.invInertia + joint.b.invInertia + joint.angularGamma)
+
+	joint.pointMass = pointMass(joint.a, joint.b)
+	joint.linearGamma, joint.linearBias = 0, neonMath.ZeroVec2D
+	if joint.LinearStiffness > 0 {
+		separation := joint.b.anchor().Sub(joint.a.anchor())
+		joint.linearGamma, joint.linearBias.X = softConstraint(joint.LinearStiffness, joint.LinearDamping, separation.X, dt)
+		_, joint.linearBias.Y = softConstraint(joint.LinearStiffness, joint.LinearDamping, separation.Y, dt)
+
+		joint.pointMass.M[0][0] += joint.linearGamma
+		joint.pointMass.M[1][1] += joint.linearGamma
+	}
+
+	if !config.WarmStarting {
+		joint.impulse, joint.angularImpulse = neonMath.ZeroVec2D, 0
+	}
+	applyJointImpulse(joint.a, joint.b, joint.impulse)
+	joint.a.applyAngularImpulse(-joint.angularImpulse)
+	joint.b.applyAngularImpulse(joint.angularImpulse)
+}
+
+func (joint *WeldJoint) solveVelocities() {
+	a, b := joint.a, joint.b
+
+	angularVelocity := b.state.AngularVelocity - a.state.AngularVelocity
+	angularImpulse := -joint.angularMass * (angularVelocity + joint.angularBias + joint.angularGamma*joint.angularImpulse)
+	joint.angularImpulse += angularImpulse
+	a.applyAngularImpulse(-angularImpulse)
+	b.applyAngularImpulse(angularImpulse)
+
+	velocity := b.velocity().Sub(a.velocity()).Add(joint.linearBias).Add(joint.impulse.Scale(joint.linearGamma))
+	impulse := solveMatrix(joint.pointMass, velocity.Scale(-1))
+	joint.impulse = joint.impulse.Add(impulse)
+	applyJointImpulse(a, b, impulse)
+}
+
+func (joint *WeldJoint) solvePositions() {
+	// only the rigid parts of the weld are put back into place, springs are meant to stretch
+	if joint.AngularStiffness <= 0 {
+		a, b := joint.solverBodies()
+		error := clamp(b.state.Angle-a.state.Angle-joint.ReferenceAngle, -maxJointAngularCorrection, maxJointAngularCorrection)
+
+		impulse := -inverse(a.invInertia+b.invInertia) * error
+		a.applyAngularPositionImpulse(-impulse)
+		b.applyAngularPositionImpulse(impulse)
+	}
+
+	if joint.LinearStiffness <= 0 {
+		solvePointPosition(joint.solverBodies())
+	}
+}
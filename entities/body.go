@@ -0,0 +1,43 @@
+package entities
+
+import (
+	neonMath "Neon/engine/math"
+	"Neon/entities/meshes"
+)
+
+// Body is simply just a 2d object that the physics engine can act on, every collider (polygons, circles, capsules and compounds) implements this
+// The engine itself only ever talks to bodies and uses the mesh type to figure out how two bodies should be collided
+type Body interface {
+	GetState() *EntityState
+	GetMeshType() meshes.MeshType
+	GetBoundingBox() neonMath.AABB
+	// MassProperties computes the mass and rotational inertia (about the centroid) of the body given its density
+	MassProperties(density float64) (float64, float64)
+	// ContainsPoint determines if a point (in world coordinates) lies within the body
+	ContainsPoint(point neonMath.Vector2D) bool
+	// RayCast casts a ray (direction must be a unit vector) against the body and returns the distance to the hit along with the normal of the surface
+	RayCast(origin, direction neonMath.Vector2D, maxDistance float64) (float64, neonMath.Vector2D, bool)
+
+	// NextTimeStep progresses the body by dt, every body already knows how to do this for its own mesh
+	NextTimeStep(dt float64)
+	// Rotate rotates the body about its centroid by dTheta
+	Rotate(dTheta float64)
+}
+
+// NewEntity creates a completely brand new body given a meshType and the set of information that defines that mesh
+// Polygons are defined by their vertices, circles by their centre and capsules by the two ends of their segment, radius is ignored for polygons
+func NewEntity(meshType meshes.MeshType, meshDefinition []neonMath.Vector2D, radius float64) Body {
+	switch meshType {
+	case meshes.MeshPolygon:
+		polygon := NewPolygon(meshDefinition)
+		return &polygon
+	case meshes.MeshCircle:
+		circle := NewCircle(meshDefinition[0], radius)
+		return &circle
+	case meshes.MeshCapsule:
+		capsule := NewCapsule(meshDefinition[0], meshDefinition[1], radius)
+		return &capsule
+	}
+
+	return nil
+}
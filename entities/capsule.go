@@ -0,0 +1,75 @@
+package entities
+
+import (
+	neonMath "Neon/engine/math"
+	"Neon/entities/meshes"
+)
+
+// Capsule (or pill) is a line segment that has been "inflated" by a radius, the rounded ends stop bodies from catching on seams between other bodies
+type Capsule struct {
+	// Endpoints of the core segment, just like polygon vertices these are relative to the centroid
+	Start, End neonMath.Vector2D
+	Radius     float64
+
+	State EntityState // Refers to the current physical state of the capsule
+}
+
+// NewCapsule generates a capsule whose core segment runs from start to end (in world coordinates), its mass is computed from the default material
+func NewCapsule(start, end neonMath.Vector2D, radius float64) Capsule {
+	centroid := start.Add(end).Scale(0.5)
+
+	capsule := Capsule{
+		Start:  start.Sub(centroid),
+		End:    end.Sub(centroid),
+		Radius: radius,
+		State: EntityState{
+			CentroidPosition: centroid,
+			Material:         DefaultMaterial,
+			Filter:           DefaultCollisionFilter,
+		},
+	}
+
+	ComputeMass(&capsule)
+	return capsule
+}
+
+// GetState returns a pointer to the physical state of the capsule
+func (capsule *Capsule) GetState() *EntityState {
+	return &capsule.State
+}
+
+// GetMeshType of a capsule is just MeshCapsule
+func (capsule *Capsule) GetMeshType() meshes.MeshType {
+	return meshes.MeshCapsule
+}
+
+// GetSegment returns the endpoints of the core segment in world coordinates
+func (capsule *Capsule) GetSegment() [2]neonMath.Vector2D {
+	return [2]neonMath.Vector2D{
+		capsule.Start.Add(capsule.State.CentroidPosition),
+		capsule.End.Add(capsule.State.CentroidPosition),
+	}
+}
+
+// NextTimeStep computes the next infinitesimal timestamp
+func (capsule *Capsule) NextTimeStep(dt float64) {
+	e := &capsule.State
+	if e.Type == StaticBody {
+		return
+	}
+
+	e.CentroidPosition = e.CentroidPosition.Add(e.Velocity.Scale(neonMath.Metre).Scale(dt))
+
+	capsule.Rotate(dt * e.AngularVelocity)
+}
+
+// Rotate rotates the capsule about its centroid by dTheta
+func (capsule *Capsule) Rotate(dTheta float64) {
+	if capsule.State.Type == StaticBody {
+		return
+	}
+
+	capsule.Start = capsule.Start.Rotate(dTheta)
+	capsule.End = capsule.End.Rotate(dTheta)
+	capsule.State.Angle += dTheta
+}
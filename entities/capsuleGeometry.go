@@ -0,0 +1,90 @@
+package entities
+
+import (
+	neonMath "Neon/engine/math"
+	"math"
+)
+
+/*
+	Elementary geometry for capsules, the main thing here is the capsule variant of SAT
+	A capsule is the minkowski sum of a segment and a circle so every projection of it is just the projection of its segment padded by the radius
+*/
+
+// AxisProjection returns the projection interval of a capsule onto an axis in world coordinates
+func (capsule *Capsule) AxisProjection(axis neonMath.Vector2D) []float64 {
+	axis = axis.Normalise()
+	segment := capsule.GetSegment()
+
+	a, b := segment[0].Dot(axis), segment[1].Dot(axis)
+	return []float64{math.Min(a, b) - capsule.Radius, math.Max(a, b) + capsule.Radius}
+}
+
+// SegmentNormal returns a unit normal to the core segment of the capsule
+func (capsule *Capsule) SegmentNormal() neonMath.Vector2D {
+	return capsule.End.Sub(capsule.Start).Normal().Normalise()
+}
+
+// CapsulePolygonSAT determines if a capsule and a polygon are intersecting and computes the corresponding MTV
+// The MTV always points from the polygon to the capsule, if the axis it was found on is one of the faces of the polygon then that face is also returned
+func CapsulePolygonSAT(capsule Capsule, poly Polygon) (neonMath.Vector2D, []int) {
+	mtv, mtvFace := neonMath.BigVec2D, []int(nil)
+	segment := capsule.GetSegment()
+
+	// testAxis projects both bodies onto the axis, if they are separated it returns false
+	// otherwise it updates the mtv if pushing the capsule along the axis is the cheapest option yet
+	testAxis := func(axis neonMath.Vector2D, face []int) bool {
+		polyProjection, capsuleProjection := poly.AxisProjection(axis), capsule.AxisProjection(axis)
+
+		// The capsule can either be pushed forwards or backwards along the axis, we want the shortest of the two
+		forwards, backwards := polyProjection[1]-capsuleProjection[0], capsuleProjection[1]-polyProjection[0]
+		if forwards <= 0 || backwards <= 0 {
+			return false
+		}
+
+		// Note that pushing the capsule out the back of a face means it isnt really the face we are touching
+		candidate := axis.Scale(forwards)
+		if backwards < forwards {
+			candidate, face = axis.Scale(-backwards), nil
+		}
+
+		// Faces of the polygon produce much better contact points, so we give them a slight preference over the other axes
+		if candidate.Length() < mtv.Length()-equalityTolerance || (face != nil && candidate.Length() < mtv.Length()+equalityTolerance) {
+			mtv, mtvFace = candidate, face
+		}
+		return true
+	}
+
+	// candidate axes: the faces of the polygon, the normal of the segment and the directions between the vertices of the polygon and the ends of the segment
+	// everything is visited by vertex index so equally good axes always resolve to the same one
+	for vertex := 0; vertex < len(poly.Vertices); vertex++ {
+		face := []int{vertex, (vertex + 1) % len(poly.Vertices)}
+		if !testAxis(poly.FaceNormal(face), face) {
+			return neonMath.ZeroVec2D, nil
+		}
+	}
+	if !testAxis(capsule.SegmentNormal(), nil) {
+		return neonMath.ZeroVec2D, nil
+	}
+	for vertex := 0; vertex < len(poly.Vertices); vertex++ {
+		for _, end := range segment {
+			if axis := end.Sub(poly.Vertices[vertex].Add(poly.State.CentroidPosition)); axis.Length() > equalityTolerance && !testAxis(axis.Normalise(), nil) {
+				return neonMath.ZeroVec2D, nil
+			}
+		}
+	}
+
+	return mtv, mtvFace
+}
+
+// GetBoundingBox computes the axis aligned bounding box of the capsule in world coordinates
+func (capsule *Capsule) GetBoundingBox() neonMath.AABB {
+	segment := capsule.GetSegment()
+	return neonMath.AABB{Min: segment[0], Max: segment[0]}.
+		Union(neonMath.AABB{Min: segment[1], Max: segment[1]}).
+		Fatten(capsule.Radius)
+}
+
+// ContainsPoint determines if a point lies within the capsule, this is just if its within the radius of the core segment
+func (capsule *Capsule) ContainsPoint(point neonMath.Vector2D) bool {
+	return point.Sub(neonMath.ClosestPointOnInterval(point, capsule.GetSegment())).Length() <= capsule.Radius
+}
@@ -0,0 +1,60 @@
+package entities
+
+import (
+	neonMath "Neon/engine/math"
+	"Neon/entities/meshes"
+)
+
+// Circle is the simplest possible body, its just a centre and a radius
+// Note that since a circle is rotationally symmetric we never need to track its vertices, its "mesh" is just the radius
+type Circle struct {
+	Radius float64
+
+	State EntityState // Refers to the current physical state of the circle
+}
+
+// NewCircle generates a new circle centred at centre, its mass is computed from the default material
+func NewCircle(centre neonMath.Vector2D, radius float64) Circle {
+	circle := Circle{
+		Radius: radius,
+		State: EntityState{
+			CentroidPosition: centre,
+			Material:         DefaultMaterial,
+			Filter:           DefaultCollisionFilter,
+		},
+	}
+
+	ComputeMass(&circle)
+	return circle
+}
+
+// GetState returns a pointer to the physical state of the circle
+func (circle *Circle) GetState() *EntityState {
+	return &circle.State
+}
+
+// GetMeshType of a circle is just MeshCircle
+func (circle *Circle) GetMeshType() meshes.MeshType {
+	return meshes.MeshCircle
+}
+
+// NextTimeStep computes the next infinitesimal timestamp, kinematic circles still move with their velocity
+func (circle *Circle) NextTimeStep(dt float64) {
+	e := &circle.State
+	if e.Type == StaticBody {
+		return
+	}
+
+	e.CentroidPosition = e.CentroidPosition.Add(e.Velocity.Scale(neonMath.Metre).Scale(dt))
+
+	circle.Rotate(dt * e.AngularVelocity)
+}
+
+// Rotate rotates the circle about its centre by dTheta, circles look exactly the same at every orientation so only the angle has to be tracked
+func (circle *Circle) Rotate(dTheta float64) {
+	if circle.State.Type == StaticBody {
+		return
+	}
+
+	circle.State.Angle += dTheta
+}
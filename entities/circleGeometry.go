@@ -0,0 +1,58 @@
+package entities
+
+import (
+	neonMath "Neon/engine/math"
+	"math"
+)
+
+/*
+	Elementary geometry required for colliding circles against other meshes
+*/
+
+// ClosestBoundaryPoint determines the point on the boundary of the polygon that is closest to point (in world coordinates)
+// It also returns the face the point lies on and whether or not the point lies within the polygon, if it does then the face is the one with the shallowest penetration
+func (polygon *Polygon) ClosestBoundaryPoint(point neonMath.Vector2D) (neonMath.Vector2D, []int, bool) {
+	closestOutside, closestOutsideDistance, outsideFace := neonMath.ZeroVec2D, math.Inf(1), []int{}
+	shallowestInside, shallowestInsideDepth, insideFace := neonMath.ZeroVec2D, math.Inf(-1), []int{}
+	inside := true
+
+	// the faces are walked in vertex order (rather than over the edge map) so ties are always broken the same way
+	for vertex := 0; vertex < len(polygon.Vertices); vertex++ {
+		edge := (vertex + 1) % len(polygon.Vertices)
+		face := polygon.GetEdgeCoordinates([]int{vertex, edge})
+		normal := neonMath.ComputeOutwardsNormal(face[0], face[1], polygon.State.CentroidPosition)
+		signedDistance := point.Sub(face[0]).Dot(normal)
+		if signedDistance > 0 {
+			inside = false
+		}
+
+		candidate := neonMath.ClosestPointOnInterval(point, face)
+		if distance := point.Sub(candidate).Length(); distance < closestOutsideDistance {
+			closestOutside, closestOutsideDistance, outsideFace = candidate, distance, []int{vertex, edge}
+		}
+		if signedDistance > shallowestInsideDepth {
+			shallowestInside, shallowestInsideDepth, insideFace = candidate, signedDistance, []int{vertex, edge}
+		}
+	}
+
+	if inside {
+		return shallowestInside, insideFace, true
+	}
+	return closestOutside, outsideFace, false
+}
+
+// FaceNormal returns the outwards facing normal of a face of the polygon
+func (polygon *Polygon) FaceNormal(face []int) neonMath.Vector2D {
+	edge := polygon.GetEdgeCoordinates(face)
+	return neonMath.ComputeOutwardsNormal(edge[0], edge[1], polygon.State.CentroidPosition)
+}
+
+// GetBoundingBox computes the axis aligned bounding box of the circle in world coordinates
+func (circle *Circle) GetBoundingBox() neonMath.AABB {
+	return neonMath.AABB{Min: circle.State.CentroidPosition, Max: circle.State.CentroidPosition}.Fatten(circle.Radius)
+}
+
+// ContainsPoint determines if a point lies within the circle
+func (circle *Circle) ContainsPoint(point neonMath.Vector2D) bool {
+	return point.Sub(circle.State.CentroidPosition).Length() <= circle.Radius
+}
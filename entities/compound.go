@@ -0,0 +1,126 @@
+package entities
+
+import (
+	neonMath "Neon/engine/math"
+	"Neon/entities/meshes"
+)
+
+// Compound is a single rigid body made up of several shapes (which can be any other body), this is how concave things like ships and vehicles are built
+// The compound owns the motion, its shapes are just dragged along with it. Each shape keeps its own material so different parts can have different densities and friction
+type Compound struct {
+	children []compoundChild
+
+	State EntityState // Refers to the current physical state of the compound
+}
+
+// compoundChild is a shape within a compound along with where it sits relative to the compound
+type compoundChild struct {
+	shape  Body
+	offset neonMath.Vector2D // from the centroid of the compound to the centroid of the shape, when the compound isnt rotated
+	angle  float64           // angle of the shape relative to the compound
+}
+
+// NewCompound groups the shapes (in world coordinates) into a single body, the mass of the compound is the total mass of its shapes
+// and its centroid is their centre of mass. Shapes shouldnt be tracked by the manager themselves once they are part of a compound
+// Note that the shapes are modified: each one is made a DynamicBody (so the compound can move it) and its velocity, force and torque are cleared,
+// the compound starts at rest and only ever reads its own state when it moves
+func NewCompound(shapes ...Body) Compound {
+	compound := Compound{
+		State: EntityState{
+			Material: DefaultMaterial,
+			Filter:   DefaultCollisionFilter,
+		},
+	}
+
+	// the centroid is weighted by the mass of each shape, if nothing has any mass then every shape just counts equally
+	centroid, totalMass := neonMath.ZeroVec2D, 0.0
+	for _, shape := range shapes {
+		state := shape.GetState()
+		centroid, totalMass = centroid.Add(state.CentroidPosition.Scale(state.Mass)), totalMass+state.Mass
+	}
+	if totalMass != 0 {
+		centroid = centroid.Scale(1.0 / totalMass)
+	} else {
+		for _, shape := range shapes {
+			centroid = centroid.Add(shape.GetState().CentroidPosition.Scale(1.0 / float64(len(shapes))))
+		}
+	}
+	compound.State.CentroidPosition = centroid
+
+	for _, shape := range shapes {
+		state := shape.GetState()
+		// shapes are moved around by the compound, so they have to be free to move but they never carry any motion of their own
+		state.Type = DynamicBody
+		state.Velocity, state.AngularVelocity = neonMath.ZeroVec2D, 0
+		state.Force, state.Torque = neonMath.ZeroVec2D, 0
+		compound.children = append(compound.children, compoundChild{shape: shape, offset: state.CentroidPosition.Sub(centroid), angle: state.Angle})
+	}
+
+	ComputeMass(&compound)
+	return compound
+}
+
+// Shapes returns every shape within the compound, the shapes are moved into place first so they are always where the compound currently is
+func (compound *Compound) Shapes() []Body {
+	shapes := make([]Body, len(compound.children))
+	for i, child := range compound.children {
+		state := child.shape.GetState()
+		state.CentroidPosition = compound.State.CentroidPosition.Add(child.offset.Rotate(compound.State.Angle))
+		if dTheta := compound.State.Angle + child.angle - state.Angle; dTheta != 0 {
+			child.shape.Rotate(dTheta)
+		}
+
+		shapes[i] = child.shape
+	}
+	return shapes
+}
+
+// GetState returns a pointer to the physical state of the compound
+func (compound *Compound) GetState() *EntityState {
+	return &compound.State
+}
+
+// GetMeshType of a compound is just MeshCompound
+func (compound *Compound) GetMeshType() meshes.MeshType {
+	return meshes.MeshCompound
+}
+
+// GetBoundingBox computes the axis aligned bounding box of the compound in world coordinates, this is just the union of the boxes of every shape
+func (compound *Compound) GetBoundingBox() neonMath.AABB {
+	box := neonMath.AABB{Min: compound.State.CentroidPosition, Max: compound.State.CentroidPosition}
+	for _, shape := range compound.Shapes() {
+		box = box.Union(shape.GetBoundingBox())
+	}
+	return box
+}
+
+// ContainsPoint determines if a point lies within any of the shapes of the compound
+func (compound *Compound) ContainsPoint(point neonMath.Vector2D) bool {
+	for _, shape := range compound.Shapes() {
+		if shape.ContainsPoint(point) {
+			return true
+		}
+	}
+	return false
+}
+
+// NextTimeStep computes the next infinitesimal timestamp, the shapes catch up with the compound whenever they are next needed
+func (compound *Compound) NextTimeStep(dt float64) {
+	e := &compound.State
+	if e.Type == StaticBody {
+		return
+	}
+
+	e.CentroidPosition = e.CentroidPosition.Add(e.Velocity.Scale(neonMath.Metre).Scale(dt))
+
+	compound.Rotate(dt * e.AngularVelocity)
+}
+
+// Rotate rotates the compound about its centroid by dTheta
+func (compound *Compound) Rotate(dTheta float64) {
+	if compound.State.Type == StaticBody {
+		return
+	}
+
+	compound.State.Angle += dTheta
+}
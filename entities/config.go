@@ -0,0 +1,4 @@
+package entities
+
+// equalityTolerance is a floating point "margin of error" for determining if two values are equal or not, mirrors the one the engine uses
+const equalityTolerance float64 = 0.0084
@@ -2,15 +2,20 @@ package entities
 
 import (
 	neonMath "Neon/engine/math"
-	"Neon/entities/meshes"
 	"math"
 )
 
-// Entity is simply just a 2d object that the physics engine can act on
-type Entity struct {
-	State EntityState
-	Mesh  meshes.Mesh
-}
+// BodyType determines how an entity responds to the world around it
+type BodyType int
+
+const (
+	// DynamicBody entities respond to forces, impulses and collisions, this is the default
+	DynamicBody BodyType = iota
+	// StaticBody entities never move and are treated as having infinite mass
+	StaticBody
+	// KinematicBody entities move with whatever velocity they are given but are treated as having infinite mass, so they push dynamic entities without being pushed back
+	KinematicBody
+)
 
 // Refers the the current state of an entity, important for physical calculations
 type EntityState struct {
@@ -18,42 +23,102 @@ type EntityState struct {
 	Velocity         neonMath.Vector2D
 	AngularVelocity  float64 // Angular velocity is of the form: (0, 0, w)
 	CentroidPosition neonMath.Vector2D
+	Angle            float64 // how far (in radians) the entity has rotated since it was created
+
+	// Force and torque accumulated over the current timestep, these are cleared once they are integrated
+	Force  neonMath.Vector2D
+	Torque float64
 
 	// Inertial stuff
 	Mass              float64
 	RotationalInertia float64
-	// Toggleable Quantity
-	NoKinetic bool
-}
+	// What the entity is made of
+	Material Material
+	// How the entity responds to forces and collisions
+	Type   BodyType
+	Filter CollisionFilter
+	// Sensors detect overlaps with other entities but never actually collide with them
+	Sensor bool
+	// Bullets are swept through each timestep so that they cant tunnel through other entities when moving quickly, this is fairly expensive
+	Bullet bool
 
-// NewEntity creates a completely brand new entity given a meshType and the set of information that defines that mesh
-func (entity *Entity) NewEntity(meshType meshes.MeshType, meshDefinition []neonMath.Vector2D) {
-	switch meshType {
-	case meshes.MeshPolygon:
-		break
-	case meshes.MeshCircle:
-		break
-	}
+	// Sleeping entities have come to rest, they are skipped by the engine until something wakes them up
+	Sleeping  bool
+	SleepTime float64 // how long (in seconds) the entity has been resting for
 }
 
 // ApplyImpulse just applies an impulse to the state,
 // Note application point is assumed to be outside the actual polygon, as such all vectors corresponding to position are relative to (0, 0) and not the centroid
 func (e *EntityState) ApplyImpulse(impulse neonMath.Vector2D, applicationPoint neonMath.Vector2D) {
-	if e.NoKinetic {
+	if e.Type != DynamicBody {
 		return
 	}
+	e.Wake()
 
 	// Actually apply the impulse
 	e.Velocity = e.Velocity.Add(impulse.Scale(1.0 / e.Mass))
 
 	if applicationPoint != neonMath.ZeroVec2D {
-		e.AngularVelocity += applicationPoint.Sub(e.CentroidPosition).Normalise().CrossMag(impulse.Normalise()) * impulse.Length() / e.RotationalInertia
+		// the lever arm is measured in metres to keep the units consistent with the rest of the solver
+		e.AngularVelocity += applicationPoint.Sub(e.CentroidPosition).Scale(1.0/neonMath.Metre).CrossMag(impulse) / e.RotationalInertia
 	}
 }
 
+// ApplyForce accumulates a force acting through the centroid of the entity, it only takes effect once the forces are integrated
+func (e *EntityState) ApplyForce(force neonMath.Vector2D) {
+	e.Force = e.Force.Add(force)
+}
+
+// ApplyForceAtPoint accumulates a force acting at a point (in world coordinates), if the point is off the centroid then the force also produces a torque
+func (e *EntityState) ApplyForceAtPoint(force neonMath.Vector2D, applicationPoint neonMath.Vector2D) {
+	e.Force = e.Force.Add(force)
+	e.Torque += applicationPoint.Sub(e.CentroidPosition).Scale(1.0 / neonMath.Metre).CrossMag(force)
+}
+
+// ApplyTorque accumulates a torque about the centroid of the entity
+func (e *EntityState) ApplyTorque(torque float64) {
+	e.Torque += torque
+}
+
+// IntegrateForces converts the accumulated force and torque into a change in velocity over dt, the accumulators are then cleared
+// Entities without a mass (or without inertia) just ignore forces, as do static, kinematic and sleeping entities
+func (e *EntityState) IntegrateForces(dt float64) {
+	if e.Type == DynamicBody && !e.Sleeping {
+		if e.Mass != 0 {
+			e.Velocity = e.Velocity.Add(e.Force.Scale(dt / e.Mass))
+		}
+		if e.RotationalInertia != 0 {
+			e.AngularVelocity += e.Torque * dt / e.RotationalInertia
+		}
+	}
+
+	e.ClearForces()
+}
+
+// ClearForces resets the force and torque accumulators
+func (e *EntityState) ClearForces() {
+	e.Force = neonMath.ZeroVec2D
+	e.Torque = 0
+}
+
+// Wake wakes the entity up if it was sleeping, its rest timer is also reset
+func (e *EntityState) Wake() {
+	e.Sleeping = false
+	e.SleepTime = 0
+}
+
+// Sleep puts the entity to sleep, sleeping entities dont move so their velocity and any accumulated forces are thrown away
+func (e *EntityState) Sleep() {
+	e.Sleeping = true
+	e.Velocity = neonMath.ZeroVec2D
+	e.AngularVelocity = 0
+	e.ClearForces()
+}
+
 // ShiftOffset moves the centroid of the entityState by offset, returns true if the centroid was shifted
+// Only dynamic entities can be shifted, static and kinematic entities are never pushed around
 func (e *EntityState) ShiftCentroid(offset neonMath.Vector2D) bool {
-	if e.NoKinetic {
+	if e.Type != DynamicBody {
 		return false
 	}
 
@@ -61,35 +126,37 @@ func (e *EntityState) ShiftCentroid(offset neonMath.Vector2D) bool {
 	return true
 }
 
-// RetrievePhysicalData fetches the physical data of the polygon, note that if the polygon is not kinetic then we say it has "infinite mass" and "infinite moment of inertia"
+// RetrievePhysicalData fetches the physical data of the polygon, note that if the polygon is static or kinematic then we say it has "infinite mass" and "infinite moment of inertia"
 func (e *EntityState) RetrievePhysicalData() (float64, float64) {
-	if e.NoKinetic {
+	if e.Type != DynamicBody {
 		return math.Inf(1), math.Inf(1)
 	}
 
 	return e.Mass, e.RotationalInertia
 }
 
-// NextTimeStep computes the next infinitesimal timestamp
+// NextTimeStep computes the next infinitesimal timestamp, kinematic polygons still move with their velocity
 func (polygon *Polygon) NextTimeStep(dt float64) {
 	// Update the actual position
 	e := &polygon.State
-	if e.NoKinetic {
+	if e.Type == StaticBody {
 		return
 	}
 
 	e.CentroidPosition = e.CentroidPosition.Add(e.Velocity.Scale(neonMath.Metre).Scale(dt))
 
-	// Simple rotational utility
-	matrixRotate := func(d neonMath.Vector2D, dTheta float64) neonMath.Vector2D {
-		return neonMath.Vector2D{
-			X: d.X*math.Cos(dTheta) - d.Y*math.Sin(dTheta),
-			Y: d.X*math.Sin(dTheta) + d.Y*math.Cos(dTheta),
-		}
-	}
 	// Compute the actual rotation of the entity
-	dTheta := dt * e.AngularVelocity
+	polygon.Rotate(dt * e.AngularVelocity)
+}
+
+// Rotate rotates the polygon about its centroid by dTheta
+func (polygon *Polygon) Rotate(dTheta float64) {
+	if polygon.State.Type == StaticBody {
+		return
+	}
+
 	for i, _ := range polygon.Vertices {
-		polygon.Vertices[i] = matrixRotate(polygon.Vertices[i], dTheta)
+		polygon.Vertices[i] = polygon.Vertices[i].Rotate(dTheta)
 	}
+	polygon.State.Angle += dTheta
 }
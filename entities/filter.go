@@ -0,0 +1,32 @@
+package entities
+
+// CollisionFilter determines which other entities an entity is allowed to collide with
+// Two entities collide if each of their masks accept the category of the other, the group overrides this entirely:
+// entities within the same positive group always collide and entities within the same negative group never collide
+type CollisionFilter struct {
+	Category uint16 // the categories the entity belongs to, this is a bitfield
+	Mask     uint16 // the categories the entity is willing to collide with
+	Group    int
+}
+
+// DefaultCollisionFilter puts an entity in the first category and lets it collide with everything
+// A filter with both its Category and Mask left as 0 (like a zero valued EntityState) is treated as having the default category and mask
+var DefaultCollisionFilter = CollisionFilter{Category: 0x0001, Mask: 0xFFFF}
+
+// ShouldCollide determines if two filters allow their entities to collide
+func (filter CollisionFilter) ShouldCollide(other CollisionFilter) bool {
+	if filter.Group != 0 && filter.Group == other.Group {
+		return filter.Group > 0
+	}
+
+	filter, other = filter.withDefaults(), other.withDefaults()
+	return filter.Mask&other.Category != 0 && other.Mask&filter.Category != 0
+}
+
+// withDefaults fills in the default category and mask if neither was set, the group is left alone
+func (filter CollisionFilter) withDefaults() CollisionFilter {
+	if filter.Category == 0 && filter.Mask == 0 {
+		filter.Category, filter.Mask = DefaultCollisionFilter.Category, DefaultCollisionFilter.Mask
+	}
+	return filter
+}
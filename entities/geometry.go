@@ -149,3 +149,19 @@ func SAT(polyA Polygon, polyB Polygon) neonMath.Vector2D {
 		}
 	}
 }
+
+// GetBoundingBox computes the axis aligned bounding box of the polygon in world coordinates
+func (polygon *Polygon) GetBoundingBox() neonMath.AABB {
+	box := neonMath.AABB{Min: neonMath.BigVec2D, Max: neonMath.BigVec2D.Scale(-1.0)}
+	for _, v := range polygon.Vertices {
+		worldVertex := v.Add(polygon.State.CentroidPosition)
+		box = box.Union(neonMath.AABB{Min: worldVertex, Max: worldVertex})
+	}
+	return box
+}
+
+// ContainsPoint determines if a point lies within the polygon, ClosestBoundaryPoint already works this out for us
+func (polygon *Polygon) ContainsPoint(point neonMath.Vector2D) bool {
+	_, _, inside := polygon.ClosestBoundaryPoint(point)
+	return inside
+}
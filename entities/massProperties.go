@@ -0,0 +1,78 @@
+package entities
+
+import (
+	neonMath "Neon/engine/math"
+	"math"
+)
+
+/*
+	Mass properties are computed from the geometry of a body and the density of its material, everything is converted into metres
+	first so the masses come out in kg and the rotational inertias in kg m^2. Inertias are always taken about the centroid of the body
+*/
+
+// ComputeMass sets the mass and rotational inertia of a body from its geometry and the density of its material
+// This should be called again whenever the material (or shape) of the body changes
+func ComputeMass(body Body) {
+	state := body.GetState()
+	state.Mass, state.RotationalInertia = body.MassProperties(state.Material.Density)
+}
+
+// MassProperties computes the mass and rotational inertia of the polygon given its density
+// The polygon is split into triangles that fan out from the centroid, each triangle then contributes its own (signed) area and inertia.
+// Walking around the boundary this is just the shoelace formula so the winding of the vertices only flips the sign of the totals
+func (polygon *Polygon) MassProperties(density float64) (float64, float64) {
+	area, inertia := 0.0, 0.0
+
+	for vertex := 0; vertex < len(polygon.Vertices); vertex++ {
+		a := polygon.Vertices[vertex].Scale(1.0 / neonMath.Metre)
+		b := polygon.Vertices[(vertex+1)%len(polygon.Vertices)].Scale(1.0 / neonMath.Metre)
+
+		triangleArea := 0.5 * a.CrossMag(b)
+		area += triangleArea
+		inertia += triangleArea * (a.Dot(a) + a.Dot(b) + b.Dot(b)) / 6.0
+	}
+
+	return density * math.Abs(area), density * math.Abs(inertia)
+}
+
+// MassProperties computes the mass and rotational inertia of the circle given its density, this is just a uniform disk
+func (circle *Circle) MassProperties(density float64) (float64, float64) {
+	radius := circle.Radius / neonMath.Metre
+	mass := density * math.Pi * radius * radius
+
+	return mass, 0.5 * mass * radius * radius
+}
+
+// MassProperties computes the mass and rotational inertia of the capsule given its density
+// The capsule is a rectangle with a semicircle on either end, the semicircles are moved to the ends of the rectangle with the parallel axis theorem
+func (capsule *Capsule) MassProperties(density float64) (float64, float64) {
+	radius := capsule.Radius / neonMath.Metre
+	length := capsule.End.Sub(capsule.Start).Length() / neonMath.Metre
+
+	rectangleMass := density * 2 * radius * length
+	circleMass := density * math.Pi * radius * radius
+
+	// the centroid of each semicircle lies 4r/3pi away from the end of the rectangle
+	halfLength, semicircleCentroid := 0.5*length, 4*radius/(3*math.Pi)
+	rectangleInertia := rectangleMass * (4*radius*radius + length*length) / 12.0
+	circleInertia := circleMass * (0.5*radius*radius + halfLength*halfLength + 2*halfLength*semicircleCentroid)
+
+	return rectangleMass + circleMass, rectangleInertia + circleInertia
+}
+
+// MassProperties computes the mass and rotational inertia of the compound, density is ignored since every shape uses the density of its own material
+// The inertia of each shape is moved from its own centroid to the centroid of the compound with the parallel axis theorem
+func (compound *Compound) MassProperties(density float64) (float64, float64) {
+	mass, inertia := 0.0, 0.0
+
+	for _, shape := range compound.Shapes() {
+		state := shape.GetState()
+		shapeMass, shapeInertia := shape.MassProperties(state.Material.Density)
+		offset := state.CentroidPosition.Sub(compound.State.CentroidPosition).Scale(1.0 / neonMath.Metre)
+
+		mass += shapeMass
+		inertia += shapeInertia + shapeMass*offset.Dot(offset)
+	}
+
+	return mass, inertia
+}
@@ -0,0 +1,75 @@
+package entities
+
+import (
+	neonMath "Neon/engine/math"
+	"math"
+	"testing"
+)
+
+func TestMassProperties(t *testing.T) {
+	// a 1m x 2m box
+	box := NewPolygon([]neonMath.Vector2D{{X: 0, Y: 0}, {X: 150, Y: 0}, {X: 150, Y: 300}, {X: 0, Y: 300}})
+	if mass, inertia := box.MassProperties(3.0); math.Abs(mass-6.0) > 1e-9 || math.Abs(inertia-6.0*5.0/12.0) > 1e-9 {
+		t.Errorf("expected a mass of 6 and inertia of 2.5 for the box, got %v and %v", mass, inertia)
+	}
+
+	// the centroid of a right angled triangle is a third of the way along each side, not the average of the vertices
+	triangle := NewPolygon([]neonMath.Vector2D{{X: 0, Y: 0}, {X: 300, Y: 0}, {X: 0, Y: 300}})
+	if triangle.State.CentroidPosition.Sub(neonMath.Vector2D{X: 100, Y: 100}).Length() > 1e-9 {
+		t.Errorf("expected the triangle to be centred on (100, 100), got %v", triangle.State.CentroidPosition)
+	}
+	// the mass should have been computed from the default material
+	if math.Abs(triangle.State.Mass-2.0*DefaultMaterial.Density) > 1e-9 {
+		t.Errorf("expected the triangle to have a mass of 2, got %v", triangle.State.Mass)
+	}
+
+	// the centroid of a deep U sits in the gap between its arms, so some of the triangles in the fan have a negative area
+	u := NewPolygon([]neonMath.Vector2D{{X: 0, Y: 0}, {X: 450, Y: 0}, {X: 450, Y: 600}, {X: 300, Y: 600}, {X: 300, Y: 150}, {X: 150, Y: 150}, {X: 150, Y: 600}, {X: 0, Y: 600}})
+	if mass, _ := u.MassProperties(1.0); math.Abs(mass-9.0) > 1e-9 {
+		t.Errorf("expected a mass of 9 for the U, got %v", mass)
+	}
+
+	circle := NewCircle(neonMath.ZeroVec2D, 150)
+	if mass, inertia := circle.MassProperties(1.0); math.Abs(mass-math.Pi) > 1e-9 || math.Abs(inertia-0.5*math.Pi) > 1e-9 {
+		t.Errorf("expected a mass of pi and inertia of pi/2 for the circle, got %v and %v", mass, inertia)
+	}
+
+	// a capsule with no length is just a circle
+	capsule := NewCapsule(neonMath.ZeroVec2D, neonMath.ZeroVec2D, 150)
+	if mass, inertia := capsule.MassProperties(1.0); math.Abs(mass-math.Pi) > 1e-9 || math.Abs(inertia-0.5*math.Pi) > 1e-9 {
+		t.Errorf("expected a mass of pi and inertia of pi/2 for the point capsule, got %v and %v", mass, inertia)
+	}
+}
+
+func TestCompoundMassProperties(t *testing.T) {
+	// two 1m boxes side by side, the right one is three times as dense
+	left := NewPolygon([]neonMath.Vector2D{{X: 0, Y: 0}, {X: 150, Y: 0}, {X: 150, Y: 150}, {X: 0, Y: 150}})
+	right := NewPolygon([]neonMath.Vector2D{{X: 150, Y: 0}, {X: 300, Y: 0}, {X: 300, Y: 150}, {X: 150, Y: 150}})
+	left.State.Material.Density, right.State.Material.Density = 1.0, 3.0
+	ComputeMass(&left)
+	ComputeMass(&right)
+
+	right.State.Type, right.State.Velocity = StaticBody, neonMath.Vector2D{X: 1, Y: 0}
+
+	// the shapes hand their motion over to the compound
+	compound := NewCompound(&left, &right)
+	if right.State.Type != DynamicBody || right.State.Velocity != neonMath.ZeroVec2D {
+		t.Errorf("shapes within a compound should be dynamic and at rest, got %v moving at %v", right.State.Type, right.State.Velocity)
+	}
+	if compound.State.CentroidPosition.Sub(neonMath.Vector2D{X: 187.5, Y: 75}).Length() > 1e-9 {
+		t.Errorf("expected the compound to be centred on its centre of mass (187.5, 75), got %v", compound.State.CentroidPosition)
+	}
+
+	// each box has an inertia of m/6 about its own centroid, which then gets moved 0.75m and 0.25m out to the centroid of the compound
+	expectedInertia := 1.0/6.0 + 1.0*0.75*0.75 + 3.0/6.0 + 3.0*0.25*0.25
+	if math.Abs(compound.State.Mass-4.0) > 1e-9 || math.Abs(compound.State.RotationalInertia-expectedInertia) > 1e-9 {
+		t.Errorf("expected a mass of 4 and inertia of %v for the compound, got %v and %v", expectedInertia, compound.State.Mass, compound.State.RotationalInertia)
+	}
+
+	// rotating the compound swings its shapes around its centroid
+	compound.Rotate(math.Pi)
+	shapes := compound.Shapes()
+	if shapes[0].GetState().CentroidPosition.Sub(neonMath.Vector2D{X: 300, Y: 75}).Length() > 1e-9 || !compound.ContainsPoint(neonMath.Vector2D{X: 290, Y: 75}) {
+		t.Errorf("expected the left box to have swung around to (300, 75), its at %v", shapes[0].GetState().CentroidPosition)
+	}
+}
@@ -0,0 +1,50 @@
+package entities
+
+import "math"
+
+// Material describes what a body is made of, the engine uses it to figure out how bouncy and how "grippy" a contact is
+type Material struct {
+	Restitution     float64 // 0 is perfectly inelastic, 1 is perfectly elastic
+	StaticFriction  float64 // surfaces stick together until the friction required exceeds StaticFriction * normal force
+	DynamicFriction float64 // after which they slide with a friction of DynamicFriction * normal force
+	Density         float64 // in kg/m^2
+}
+
+// DefaultMaterial is the material every body starts off with, a fairly dull material with a little bounce and some grip
+var DefaultMaterial = Material{Restitution: 0.2, StaticFriction: 0.6, DynamicFriction: 0.4, Density: 1.0}
+
+// Some common materials
+var (
+	Rubber   = Material{Restitution: 0.8, StaticFriction: 1.0, DynamicFriction: 0.8, Density: 1.1}
+	Ice      = Material{Restitution: 0.1, StaticFriction: 0.05, DynamicFriction: 0.03, Density: 0.92}
+	Concrete = Material{Restitution: 0.2, StaticFriction: 0.8, DynamicFriction: 0.6, Density: 2.4}
+	Wood     = Material{Restitution: 0.4, StaticFriction: 0.5, DynamicFriction: 0.3, Density: 0.7}
+	Steel    = Material{Restitution: 0.5, StaticFriction: 0.7, DynamicFriction: 0.5, Density: 7.8}
+)
+
+// MixingRule determines how a property of two materials is combined into a single value for a contact between them
+type MixingRule int
+
+const (
+	MixAverage MixingRule = iota
+	MixMin
+	MixMax
+	MixMultiply
+	MixGeometricMean
+)
+
+// Mix combines two values according to the rule
+func (rule MixingRule) Mix(a, b float64) float64 {
+	switch rule {
+	case MixMin:
+		return math.Min(a, b)
+	case MixMax:
+		return math.Max(a, b)
+	case MixMultiply:
+		return a * b
+	case MixGeometricMean:
+		return math.Sqrt(a * b)
+	}
+
+	return (a + b) / 2.0
+}
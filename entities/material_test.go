@@ -0,0 +1,28 @@
+package entities
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMixingRules(t *testing.T) {
+	tests := []struct {
+		rule     MixingRule
+		a, b     float64
+		expected float64
+	}{
+		{MixAverage, 0.2, 0.8, 0.5},
+		{MixMin, 0.2, 0.8, 0.2},
+		{MixMax, 0.2, 0.8, 0.8},
+		{MixMultiply, 0.2, 0.8, 0.16},
+		{MixGeometricMean, 0.2, 0.8, 0.4},
+		// ice on anything should be slippery when the smallest friction wins
+		{MixMin, Ice.DynamicFriction, Concrete.DynamicFriction, Ice.DynamicFriction},
+	}
+
+	for _, test := range tests {
+		if mixed := test.rule.Mix(test.a, test.b); math.Abs(mixed-test.expected) > 1e-9 {
+			t.Errorf("rule %v mixing %v and %v: expected %v, got %v", test.rule, test.a, test.b, test.expected, mixed)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package entities
 
 import (
 	neonMath "Neon/engine/math"
+	"Neon/entities/meshes"
 )
 
 // Polygon data structure represents a polygon internally using a graph
@@ -16,14 +17,10 @@ type Polygon struct {
 	prevID int
 }
 
-// Simple method to generate a new polygon
+// Simple method to generate a new polygon, the polygon is centred on its centre of mass and its mass is computed from the default material
 func NewPolygon(vertices []neonMath.Vector2D) Polygon {
 	// First compute the centroid
-	centroid := neonMath.ZeroVec2D
-	for _, vertex := range vertices {
-		centroid = centroid.Add(vertex)
-	}
-	centroid = centroid.Scale(1.0 / float64(len(vertices)))
+	centroid := neonMath.ComputeAreaCentroid(vertices)
 
 	// The polygon we want to generate
 	generatedPolygon := Polygon{
@@ -31,6 +28,8 @@ func NewPolygon(vertices []neonMath.Vector2D) Polygon {
 		Edges:    make(map[int][]int),
 		State: EntityState{
 			CentroidPosition: centroid,
+			Material:         DefaultMaterial,
+			Filter:           DefaultCollisionFilter,
 		},
 	}
 
@@ -45,9 +44,20 @@ func NewPolygon(vertices []neonMath.Vector2D) Polygon {
 		generatedPolygon.prevID++
 	}
 
+	ComputeMass(&generatedPolygon)
 	return generatedPolygon
 }
 
+// GetState returns a pointer to the physical state of the polygon
+func (polygon *Polygon) GetState() *EntityState {
+	return &polygon.State
+}
+
+// GetMeshType of a polygon is just MeshPolygon
+func (polygon *Polygon) GetMeshType() meshes.MeshType {
+	return meshes.MeshPolygon
+}
+
 // Returns the endpoints of the interval defined by an edge
 func (polygon *Polygon) GetEdgeCoordinates(face []int) [2]neonMath.Vector2D {
 	return [2]neonMath.Vector2D{
@@ -0,0 +1,123 @@
+package entities
+
+import (
+	neonMath "Neon/engine/math"
+	"math"
+)
+
+/*
+	Ray casting against each of the meshes, a ray starts at origin and travels along direction (a unit vector) for at most maxDistance
+	Every method returns the distance along the ray to the hit and the outwards normal of the surface that was hit, rays that start inside a body never hit it
+*/
+
+// RayCast casts a ray against the polygon, the ray is clipped against the half plane of every face and whatever is left of it is inside the polygon
+func (polygon *Polygon) RayCast(origin, direction neonMath.Vector2D, maxDistance float64) (float64, neonMath.Vector2D, bool) {
+	lower, upper := 0.0, maxDistance
+	normal := neonMath.ZeroVec2D
+
+	// faces are clipped in vertex order, a ray straight through a corner always reports the same face
+	for vertex := 0; vertex < len(polygon.Vertices); vertex++ {
+		edge := (vertex + 1) % len(polygon.Vertices)
+		face := polygon.GetEdgeCoordinates([]int{vertex, edge})
+		faceNormal := neonMath.ComputeOutwardsNormal(face[0], face[1], polygon.State.CentroidPosition)
+		numerator, denominator := face[0].Sub(origin).Dot(faceNormal), direction.Dot(faceNormal)
+
+		switch {
+		case denominator == 0 && numerator < 0:
+			// parallel to the face and outside of it
+			return 0, neonMath.ZeroVec2D, false
+		case denominator < 0 && numerator < lower*denominator:
+			// the ray is entering the polygon through this face
+			lower, normal = numerator/denominator, faceNormal
+		case denominator > 0 && numerator < upper*denominator:
+			// the ray is leaving the polygon through this face
+			upper = numerator / denominator
+		}
+
+		if upper < lower {
+			return 0, neonMath.ZeroVec2D, false
+		}
+	}
+
+	// if the ray never entered through a face then it started inside the polygon
+	if normal == neonMath.ZeroVec2D {
+		return 0, neonMath.ZeroVec2D, false
+	}
+	return lower, normal, true
+}
+
+// RayCast casts a ray against the circle
+func (circle *Circle) RayCast(origin, direction neonMath.Vector2D, maxDistance float64) (float64, neonMath.Vector2D, bool) {
+	return rayCastCircle(origin, direction, maxDistance, circle.State.CentroidPosition, circle.Radius)
+}
+
+// RayCast casts a ray against the capsule, since a capsule is just a rectangle with a circle on either end the ray is cast against each of those
+func (capsule *Capsule) RayCast(origin, direction neonMath.Vector2D, maxDistance float64) (float64, neonMath.Vector2D, bool) {
+	segment := capsule.GetSegment()
+	if origin.Sub(neonMath.ClosestPointOnInterval(origin, segment)).Length() <= capsule.Radius {
+		return 0, neonMath.ZeroVec2D, false
+	}
+
+	closest, normal, hit := math.Inf(1), neonMath.ZeroVec2D, false
+	for _, end := range segment {
+		if distance, endNormal, endHit := rayCastCircle(origin, direction, maxDistance, end, capsule.Radius); endHit && distance < closest {
+			closest, normal, hit = distance, endNormal, true
+		}
+	}
+
+	// the sides of the capsule are just the segment pushed out along its normal
+	axis := segment[1].Sub(segment[0])
+	if axis.Length() == 0 {
+		return closest, normal, hit
+	}
+	for _, sideNormal := range []neonMath.Vector2D{capsule.SegmentNormal(), capsule.SegmentNormal().Scale(-1)} {
+		denominator := direction.Dot(sideNormal)
+		if denominator >= 0 {
+			continue
+		}
+
+		side := segment[0].Add(sideNormal.Scale(capsule.Radius))
+		distance := side.Sub(origin).Dot(sideNormal) / denominator
+		point := origin.Add(direction.Scale(distance))
+		if along := point.Sub(side).ScalarProject(axis); distance >= 0 && distance <= maxDistance && along >= 0 && along <= 1 && distance < closest {
+			closest, normal, hit = distance, sideNormal, true
+		}
+	}
+
+	return closest, normal, hit
+}
+
+// RayCast casts a ray against the compound, the closest hit against any of its shapes wins
+func (compound *Compound) RayCast(origin, direction neonMath.Vector2D, maxDistance float64) (float64, neonMath.Vector2D, bool) {
+	if compound.ContainsPoint(origin) {
+		return 0, neonMath.ZeroVec2D, false
+	}
+
+	closest, normal, hit := math.Inf(1), neonMath.ZeroVec2D, false
+	for _, shape := range compound.Shapes() {
+		if distance, shapeNormal, shapeHit := shape.RayCast(origin, direction, maxDistance); shapeHit && distance < closest {
+			closest, normal, hit = distance, shapeNormal, true
+		}
+	}
+
+	if !hit {
+		return 0, neonMath.ZeroVec2D, false
+	}
+	return closest, normal, hit
+}
+
+// rayCastCircle casts a ray against a circle, this is just solving a quadratic for where the ray is exactly radius away from the centre
+func rayCastCircle(origin, direction neonMath.Vector2D, maxDistance float64, centre neonMath.Vector2D, radius float64) (float64, neonMath.Vector2D, bool) {
+	offset := origin.Sub(centre)
+	b, c := offset.Dot(direction), offset.Dot(offset)-radius*radius
+	discriminant := b*b - c
+	if c <= 0 || discriminant < 0 {
+		return 0, neonMath.ZeroVec2D, false
+	}
+
+	distance := -b - math.Sqrt(discriminant)
+	if distance < 0 || distance > maxDistance {
+		return 0, neonMath.ZeroVec2D, false
+	}
+	return distance, origin.Add(direction.Scale(distance)).Sub(centre).Normalise(), true
+}
@@ -22,14 +22,14 @@ func definePolygons() []*entities.Polygon {
 	poly := entities.NewPolygon([]neonMath.Vector2D{
 		{X: 10, Y: 110}, {X: 110, Y: 110}, {X: 110, Y: 10}, {X: 10, Y: 10},
 	})
-	poly.State.Mass = 2.0
-	poly.State.RotationalInertia = 2.0
+	poly.State.Material.Density = 4.5
+	entities.ComputeMass(&poly)
 
 	specialPoly := entities.NewPolygon([]neonMath.Vector2D{
 		{X: 200, Y: 300}, {X: 300, Y: 300}, {X: 300, Y: 200}, {X: 200, Y: 200},
 	})
-	specialPoly.State.Mass = 2.0
-	specialPoly.State.RotationalInertia = 2.0
+	specialPoly.State.Material.Density = 4.5
+	entities.ComputeMass(&specialPoly)
 
 	poly.State.AngularVelocity = 1.0
 	specialPoly.State.AngularVelocity = 0.1
@@ -38,8 +38,8 @@ func definePolygons() []*entities.Polygon {
 		{X: 600, Y: 300}, {X: 700, Y: 300}, {X: 700, Y: 200}, {X: 600, Y: 200},
 	})
 
-	translationalPoly.State.Mass = 2.1
-	translationalPoly.State.RotationalInertia = 2.0
+	translationalPoly.State.Material.Density = 4.7
+	entities.ComputeMass(&translationalPoly)
 	translationalPoly.State.Velocity = neonMath.Vector2D{X: -0.5}
 	poly.State.Velocity = neonMath.Vector2D{X: 0.5, Y: 0.5}
 
@@ -52,10 +52,10 @@ func defineCornerPolygons(height, width float64) []*entities.Polygon {
 	left := entities.NewPolygon([]neonMath.Vector2D{{X: -100, Y: 1}, {X: -100, Y: height - 1}, {X: 1, Y: height - 1}, {X: 1, Y: 0}})
 	right := entities.NewPolygon([]neonMath.Vector2D{{X: width + 100, Y: 1}, {X: width + 100, Y: height - 1}, {X: width - 1, Y: height - 1}, {X: width - 1, Y: 0}})
 
-	top.State.NoKinetic = true
-	bottom.State.NoKinetic = true
-	left.State.NoKinetic = true
-	right.State.NoKinetic = true
+	top.State.Type = entities.StaticBody
+	bottom.State.Type = entities.StaticBody
+	left.State.Type = entities.StaticBody
+	right.State.Type = entities.StaticBody
 
 	return []*entities.Polygon{&top, &bottom, &left, &right}
 }
@@ -75,16 +75,22 @@ func run() {
 	intermediateCanvas := pixelgl.NewCanvas(win.Bounds())
 	imd := imdraw.New(nil)
 
-	physicsPolys := append(definePolygons(), defineCornerPolygons(win.Bounds().H(), win.Bounds().W())...)
+	bodyPolys := definePolygons()
+	physicsPolys := append(bodyPolys, defineCornerPolygons(win.Bounds().H(), win.Bounds().W())...)
 	drawablePolys := []Polygon{}
+	physicsBodies := []entities.Body{}
 
 	for _, poly := range physicsPolys {
 		drawablePolys = append(drawablePolys, Polygon{internal: poly, colour: color.NRGBA{R: 228, G: 233, B: 242, A: 255}})
+		physicsBodies = append(physicsBodies, poly)
 	}
 
 	// hook em up to the manager
 	physicsManager := engine.NewPhysicsManager()
-	physicsManager.BeginTracking(physicsPolys...)
+	physicsManager.BeginTracking(physicsBodies...)
+
+	// the 3 bodies attract each other, the walls are left out as they are static
+	physicsManager.AddForceGenerator(engine.NBodyGravity{G: 0.5, Softening: 0.2}, physicsBodies[:len(bodyPolys)]...)
 
 	// Callback for just drawing in the collision points
 	physicsManager.AddCallback(func(manifold engine.ContactManifold) {